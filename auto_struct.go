@@ -1,20 +1,51 @@
 package autostruct
 
-import "reflect"
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"reflect"
+	"time"
+)
 
 const defaultTag = "auto"
 
-type option func(*config)
+type Option func(*config)
+
+type SetterFunc func(ctx context.Context, v reflect.Value, value string) error
 
 type config struct {
+	ctx      context.Context
 	tag      string
-	cache    *cache
+	cache    Cache
 	deepCopy bool
+	strict   bool
+	setters  map[reflect.Type]SetterFunc
+	clock    func() time.Time
+	fsys     fs.FS
+	secretFS fs.FS
+	profile  string
+	profiles map[string]struct{}
+	seqs     *sequences
+	rand     *rand.Rand
+	seed     uint64
+	seeded   bool
+	parallel int
+	report   *Report
+	logger   *slog.Logger
+	scopes   []reflect.Value
+	path     []string
+	refs     *referenceScope
+	volatile bool
+	unstable map[referenceKey]bool
 }
 
-func newConfig(opts ...option) *config {
+func newConfig(ctx context.Context, opts ...Option) *config {
 	cfg := &config{
-		tag: defaultTag,
+		ctx:   ctx,
+		tag:   defaultTag,
+		clock: time.Now,
 	}
 
 	for _, opt := range opts {
@@ -24,36 +55,169 @@ func newConfig(opts ...option) *config {
 	return cfg
 }
 
-func WithTag(tag string) option {
+func (c *config) fill(v reflect.Value) error {
+	c.volatile = false
+
+	return withReferenceScope(c, func() error {
+		return structFieldsSetter(c, v)
+	})
+}
+
+func WithTag(tag string) Option {
 	return func(c *config) {
 		c.tag = tag
 	}
 }
 
-func WithCache(cache *cache) option {
+func WithCache(cache Cache) Option {
 	return func(c *config) {
 		c.cache = cache
 	}
 }
 
-func WithDeepCopy() option {
+func WithDeepCopy() Option {
 	return func(c *config) {
 		c.deepCopy = true
 	}
 }
 
-func Set(v any, opts ...option) error {
-	return structFieldsSetter(newConfig(opts...), reflect.ValueOf(v))
+func WithStrict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+func WithSetter(typ reflect.Type, fn SetterFunc) Option {
+	return func(c *config) {
+		if c.setters == nil {
+			c.setters = make(map[reflect.Type]SetterFunc)
+		}
+		c.setters[typ] = fn
+	}
+}
+
+func WithClock(clock func() time.Time) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+func WithFS(fsys fs.FS) Option {
+	return func(c *config) {
+		c.fsys = fsys
+	}
+}
+
+func WithSecretFS(fsys fs.FS) Option {
+	return func(c *config) {
+		c.secretFS = fsys
+	}
+}
+
+func WithProfile(profile string) Option {
+	return func(c *config) {
+		c.profile = profile
+		if profile != "" {
+			WithProfiles(profile)(c)
+		}
+	}
+}
+
+func WithProfiles(profiles ...string) Option {
+	return func(c *config) {
+		if c.profiles == nil {
+			c.profiles = make(map[string]struct{})
+		}
+		for _, profile := range profiles {
+			c.profiles[profile] = struct{}{}
+		}
+	}
+}
+
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.rand = rand.New(rand.NewPCG(seed, 0))
+		c.seed = seed
+		c.seeded = true
+	}
+}
+
+func WithParallel(workers int) Option {
+	return func(c *config) {
+		c.parallel = workers
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+func Options(opts ...Option) Option {
+	return func(c *config) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
+
+func Set(v any, opts ...Option) error {
+	return SetContext(context.Background(), v, opts...)
+}
+
+func SetContext(ctx context.Context, v any, opts ...Option) error {
+	return newConfig(ctx, opts...).fill(reflect.ValueOf(v))
 }
 
-func MustSet(v any, opts ...option) {
+func MustSet(v any, opts ...Option) {
 	if err := Set(v, opts...); err != nil {
 		panic(err)
 	}
 }
 
-func New[T any](opts ...option) T {
+func New[T any](opts ...Option) T {
 	var v T
 	MustSet(&v, opts...)
 	return v
 }
+
+func NewPtr[T any](opts ...Option) *T {
+	v := new(T)
+	MustSet(v, opts...)
+	return v
+}
+
+func NewSlice[T any](n int, opts ...Option) []T {
+	cfg := newConfig(context.Background(), opts...)
+
+	s := make([]T, n)
+	for i := range s {
+		if err := cfg.fill(reflect.ValueOf(&s[i])); err != nil {
+			panic(err)
+		}
+	}
+
+	return s
+}
+
+func NewMap[K comparable, V any](keys []K, opts ...Option) map[K]V {
+	cfg := newConfig(context.Background(), opts...)
+
+	m := make(map[K]V, len(keys))
+	for _, key := range keys {
+		var v V
+		if err := cfg.fill(reflect.ValueOf(&v)); err != nil {
+			panic(err)
+		}
+		m[key] = v
+	}
+
+	return m
+}
+
+func NewContext[T any](ctx context.Context, opts ...Option) (T, error) {
+	var v T
+	err := SetContext(ctx, &v, opts...)
+	return v, err
+}
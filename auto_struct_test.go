@@ -1,13 +1,33 @@
 package autostruct
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"math/rand/v2"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 type Basic struct {
@@ -439,6 +459,1729 @@ func Test_New(t *testing.T) {
 	})
 }
 
+func Test_WithStrict(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var v Test
+		if err := Set(&v, WithStrict()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"unknown-command", &struct {
+			Slice []string `auto:"lenght(5),repeat(1)"`
+		}{}},
+		{"non-numeric-len", &struct {
+			Slice []string `auto:"len(five),repeat(1)"`
+		}{}},
+		{"non-numeric-chan", &struct {
+			Chan chan int `auto:"chan(x)"`
+		}{}},
+		{"conflicting-commands", &struct {
+			Slice []string `auto:"json([\"1\"]),repeat(1)"`
+		}{}},
+		{"duplicate-command", &struct {
+			Slice []string `auto:"len(1),len(2)"`
+		}{}},
+		{"trailing-garbage", &struct {
+			Slice []string `auto:"len(1),repeat(1),oops"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(tt.v); err != nil {
+				t.Fatalf("non-strict: unexpected error: %v", err)
+			}
+
+			err := Set(tt.v, WithStrict())
+
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("expected field error, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_SetContext(t *testing.T) {
+	type tenantKey struct{}
+
+	type Tenant string
+
+	type Request struct {
+		Tenant Tenant   `auto:"default"`
+		Items  []string `auto:"len(1000),repeat(item)"`
+	}
+
+	tenantSetter := WithSetter(reflect.TypeOf(Tenant("")), func(ctx context.Context, v reflect.Value, value string) error {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			value = tenant
+		}
+		v.SetString(value)
+		return nil
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+		act, err := NewContext[Request](ctx, tenantSetter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if act.Tenant != "acme" {
+			t.Errorf("expected tenant [acme], got [%s]", act.Tenant)
+		}
+
+		if len(act.Items) != 1000 {
+			t.Errorf("expected 1000 items, got %d", len(act.Items))
+		}
+	})
+
+	t.Run("success-context-clock-and-rand", func(t *testing.T) {
+		type Event struct {
+			At    time.Time `auto:"now"`
+			Day   time.Time `auto:"today+24h"`
+			Score int       `auto:"rand(1,1000000)"`
+			Rolls []int     `auto:"len(200),repeat(rand(1,6))"`
+		}
+
+		fixed := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+		fill := func() Event {
+			ctx := ContextWithClock(context.Background(), func() time.Time { return fixed })
+			ctx = ContextWithRand(ctx, rand.New(rand.NewPCG(1, 2)))
+
+			act, err := NewContext[Event](ctx, WithClock(time.Now), WithParallel(4), WithCache(NewCache()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return act
+		}
+
+		act := fill()
+		if !act.At.Equal(fixed) || !act.Day.Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected times from the context clock, got [%s] [%s]", act.At, act.Day)
+		}
+
+		if again := fill(); !cmp.Equal(act, again) {
+			t.Error("expected the context random source to make values reproducible")
+		}
+	})
+
+	t.Run("success-custom-setter-not-cached", func(t *testing.T) {
+		cached := NewCache()
+
+		for _, tenant := range []string{"acme", "globex"} {
+			ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+
+			act, err := NewContext[Request](ctx, tenantSetter, WithCache(cached))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(act.Tenant) != tenant {
+				t.Errorf("expected tenant [%s], got [%s]", tenant, act.Tenant)
+			}
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewContext[Request](ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	})
+}
+
+func Test_TimeExpressions(t *testing.T) {
+	type Times struct {
+		Now          time.Time  `auto:"now"`
+		Tomorrow     *time.Time `auto:"now+24h"`
+		Yesterday    time.Time  `auto:"today-24h"`
+		Today        time.Time  `auto:"value(today),tz(Europe/Berlin)"`
+		StartOfMonth time.Time  `auto:"startOfMonth"`
+		Unix         time.Time  `auto:"unix(1700000000)"`
+		UnixMilli    time.Time  `auto:"unixmilli(1700000000123)"`
+		Fixed        time.Time  `auto:"value(2024-12-09 02:20:35),layout(DateTime),tz(Europe/Berlin)"`
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	now := time.Date(2024, 12, 9, 23, 30, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("success", func(t *testing.T) {
+		act := New[Times](WithClock(clock))
+
+		exp := Times{
+			Now:          now,
+			Tomorrow:     toPtr(now.Add(24 * time.Hour)),
+			Yesterday:    time.Date(2024, 12, 8, 0, 0, 0, 0, time.UTC),
+			Today:        time.Date(2024, 12, 10, 0, 0, 0, 0, berlin),
+			StartOfMonth: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+			Unix:         time.Unix(1700000000, 0),
+			UnixMilli:    time.UnixMilli(1700000000123),
+			Fixed:        time.Date(2024, 12, 9, 2, 20, 35, 0, berlin),
+		}
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+
+	t.Run("success-with-cache", func(t *testing.T) {
+		cached := NewCache()
+		_ = New[Times](WithCache(cached), WithClock(clock))
+
+		later := now.Add(time.Hour)
+		act := New[Times](WithCache(cached), WithClock(func() time.Time { return later }))
+
+		if !act.Now.Equal(later) {
+			t.Errorf("expected [%s], got [%s]", later, act.Now)
+		}
+
+		if !act.Tomorrow.Equal(later.Add(24 * time.Hour)) {
+			t.Errorf("expected [%s], got [%s]", later.Add(24*time.Hour), act.Tomorrow)
+		}
+	})
+}
+
+func Test_NumericLiterals(t *testing.T) {
+	type Numbers struct {
+		Hex       int     `auto:"0x1F"`
+		Octal     uint32  `auto:"0o755"`
+		Binary    int8    `auto:"0b101"`
+		Separated int64   `auto:"1_000_000"`
+		Leading   int     `auto:"010"`
+		Padded    uint8   `auto:"08"`
+		Negative  int     `auto:"-0x10"`
+		Float     float64 `auto:"1_000.5"`
+		KiB       int     `auto:"bytes(64KiB)"`
+		GB        uint64  `auto:"bytes(1.5GB)"`
+		SI        int32   `auto:"si(2.5k)"`
+		Milli     float64 `auto:"si(250m)"`
+		Percent   float32 `auto:"percent(80%)"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		act := New[Numbers](WithStrict())
+
+		exp := Numbers{
+			Hex:       31,
+			Octal:     0o755,
+			Binary:    5,
+			Separated: 1000000,
+			Leading:   10,
+			Padded:    8,
+			Negative:  -16,
+			Float:     1000.5,
+			KiB:       64 * 1024,
+			GB:        1500000000,
+			SI:        2500,
+			Milli:     0.25,
+			Percent:   0.8,
+		}
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"overflow", &struct {
+			Size int8 `auto:"bytes(1KiB)"`
+		}{}},
+		{"negative-unsigned", &struct {
+			Size uint `auto:"si(-1k)"`
+		}{}},
+		{"fraction", &struct {
+			Size int `auto:"bytes(1.5B)"`
+		}{}},
+		{"unknown-unit", &struct {
+			Size int `auto:"bytes(1XB)"`
+		}{}},
+		{"leading-underscore", &struct {
+			N int `auto:"_5"`
+		}{}},
+		{"trailing-underscore", &struct {
+			N int `auto:"5_"`
+		}{}},
+		{"double-underscore", &struct {
+			N uint `auto:"1__000"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(tt.v); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func Test_StringSources(t *testing.T) {
+	type Strings struct {
+		Early    string  `auto:"template({{if .Port}}{{.Host}}{{end}})"`
+		EarlyB   []byte  `auto:"template({{.Host}})"`
+		Host     string  `auto:"localhost"`
+		Port     int     `auto:"8080"`
+		Quoted   string  `auto:"quote(\"line1\\n\\tline2 (\\u00e9)\")"`
+		File     string  `auto:"file(motd.txt)"`
+		FileRaw  []byte  `auto:"file(motd.txt)"`
+		Base64   string  `auto:"base64(aGVsbG8=)"`
+		Base64B  []byte  `auto:"base64(aGVsbG8=)"`
+		Hex      *string `auto:"hex(68656c6c6f)"`
+		HexB     []byte  `auto:"hex(68656c6c6f)"`
+		Template string  `auto:"template(http://{{.Host}}:{{.Port}})"`
+	}
+
+	fsys := fstest.MapFS{
+		"motd.txt": {Data: []byte("welcome")},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		act := New[Strings](WithFS(fsys), WithStrict())
+
+		exp := Strings{
+			Early:    "localhost",
+			EarlyB:   []byte("localhost"),
+			Host:     "localhost",
+			Port:     8080,
+			Quoted:   "line1\n\tline2 (\u00e9)",
+			File:     "welcome",
+			FileRaw:  []byte("welcome"),
+			Base64:   "hello",
+			Base64B:  []byte("hello"),
+			Hex:      toPtr("hello"),
+			HexB:     []byte("hello"),
+			Template: "http://localhost:8080",
+		}
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"missing-file", &struct {
+			File string `auto:"file(missing.txt)"`
+		}{}},
+		{"invalid-base64", &struct {
+			Base64 string `auto:"base64(!!!)"`
+		}{}},
+		{"invalid-quote", &struct {
+			Quoted string `auto:"quote(\"\\q\")"`
+		}{}},
+		{"missing-template-field", &struct {
+			Template string `auto:"template({{.Missing}})"`
+		}{}},
+		{"template-cycle", &struct {
+			A string `auto:"template({{.B}})"`
+			B string `auto:"template({{.A}})"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(tt.v, WithFS(fsys)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func Test_References(t *testing.T) {
+	type Server struct {
+		Host string `auto:"localhost"`
+		Port int    `auto:"8080"`
+	}
+
+	type Worker struct {
+		Name    string `auto:"template(worker-{{.ID}})"`
+		ID      int    `auto:"1"`
+		Timeout int    `auto:"expr(Limits.Timeout * 2)"`
+	}
+
+	type Limits struct {
+		Timeout int `auto:"10"`
+	}
+
+	type Config struct {
+		URL        string        `auto:"fmt(http://%s:%d, Server.Host, Server.Port)"`
+		Listen     *string       `auto:"fmt(:%d, Server.Port)"`
+		Server     Server        `auto:"struct"`
+		Backup     Server        `auto:"ref(Server)"`
+		Port       string        `auto:"ref(Server.Port)"`
+		Retries    int           `auto:"expr(MaxRetries - 1)"`
+		Ratio      float64       `auto:"expr(Retries / 4.0)"`
+		Timeout    time.Duration `auto:"expr(Interval * 3)"`
+		Interval   time.Duration `auto:"5s"`
+		Verbose    bool          `auto:"expr(Retries > 2 && Server.Port != 80)"`
+		Limits     Limits        `auto:"struct"`
+		Workers    []Worker      `auto:"len(2),repeat(struct)"`
+		MaxRetries int           `auto:"expr((Server.Port - 8000) / 20)"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		act := New[Config](WithStrict())
+
+		exp := Config{
+			URL:        "http://localhost:8080",
+			Listen:     toPtr(":8080"),
+			Server:     Server{Host: "localhost", Port: 8080},
+			Backup:     Server{Host: "localhost", Port: 8080},
+			Port:       "8080",
+			Retries:    3,
+			Ratio:      0.75,
+			Timeout:    15 * time.Second,
+			Interval:   5 * time.Second,
+			Verbose:    true,
+			Limits:     Limits{Timeout: 10},
+			Workers:    []Worker{{Name: "worker-1", ID: 1, Timeout: 20}, {Name: "worker-1", ID: 1, Timeout: 20}},
+			MaxRetries: 4,
+		}
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+
+	t.Run("success-with-cache", func(t *testing.T) {
+		cached := NewCache()
+		_ = New[Config](WithCache(cached))
+		act := New[Config](WithCache(cached))
+
+		if act.URL != "http://localhost:8080" || act.Retries != 3 {
+			t.Errorf("unexpected cached values: %+v", act)
+		}
+	})
+
+	t.Run("error-by-value", func(t *testing.T) {
+		v := struct {
+			A int `auto:"ref(B)"`
+			B int
+		}{B: 2}
+
+		if err := Set(v); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"cycle", &struct {
+			A int `auto:"ref(B)"`
+			B int `auto:"expr(C + 1)"`
+			C int `auto:"ref(A)"`
+		}{}},
+		{"unknown-field", &struct {
+			A int `auto:"ref(Missing)"`
+		}{}},
+		{"type-mismatch", &struct {
+			A string `auto:"abc"`
+			B int    `auto:"ref(A)"`
+		}{}},
+		{"not-integer", &struct {
+			A int `auto:"expr(1 / 2.0)"`
+		}{}},
+		{"division-by-zero", &struct {
+			A int `auto:"expr(1 / 0)"`
+		}{}},
+		{"invalid-operands", &struct {
+			A string `auto:"abc"`
+			B int    `auto:"expr(A * 2)"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fe *FieldError
+			if err := Set(tt.v); !errors.As(err, &fe) {
+				t.Errorf("expected field error, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_WithProfile(t *testing.T) {
+	type Profiled struct {
+		Level   string            `auto:"dev(debug),prod(info),value(warn)"`
+		Hosts   []string          `auto:"len(2),repeat(localhost),prod(example.com)"`
+		Ports   []int             `auto:"json([8080]),prod([80, 443])"`
+		Limits  map[string]int    `auto:"value(a:1)" auto.prod:"len(2),value(a:10,b:20)"`
+		Since   time.Time         `auto:"unix(0),prod(1700000000)"`
+		Timeout time.Duration     `auto:"1s" auto.dev:"1m"`
+		Labels  map[string]string `auto:"value(env:none)"`
+		Workers int               `auto:"dev(1),prod(2)"`
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		exp     Profiled
+	}{
+		{"default", "", Profiled{
+			Level:   "warn",
+			Hosts:   []string{"localhost", "localhost"},
+			Ports:   []int{8080},
+			Limits:  map[string]int{"a": 1},
+			Since:   time.Unix(0, 0),
+			Timeout: time.Second,
+			Labels:  map[string]string{"env": "none"},
+		}},
+		{"dev", "dev", Profiled{
+			Level:   "debug",
+			Hosts:   []string{"localhost", "localhost"},
+			Ports:   []int{8080},
+			Limits:  map[string]int{"a": 1},
+			Since:   time.Unix(0, 0),
+			Timeout: time.Minute,
+			Labels:  map[string]string{"env": "none"},
+			Workers: 1,
+		}},
+		{"prod", "prod", Profiled{
+			Level:   "info",
+			Hosts:   []string{"example.com", "example.com"},
+			Ports:   []int{80, 443},
+			Limits:  map[string]int{"a": 10, "b": 20},
+			Since:   time.Unix(1700000000, 0),
+			Timeout: time.Second,
+			Labels:  map[string]string{"env": "none"},
+			Workers: 2,
+		}},
+	}
+
+	cached := NewCache()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				act := New[Profiled](WithProfile(tt.profile), WithProfiles("dev", "prod"), WithStrict(), WithCache(cached))
+
+				if !cmp.Equal(tt.exp, act) {
+					t.Error(cmp.Diff(tt.exp, act))
+				}
+			}
+		})
+	}
+
+	t.Run("strict-undeclared-profile", func(t *testing.T) {
+		var v Profiled
+		if err := Set(&v, WithProfile("prod"), WithStrict()); err == nil {
+			t.Error("expected error for undeclared profile [dev]")
+		}
+	})
+}
+
+func Test_Factory(t *testing.T) {
+	type User struct {
+		ID    int    `auto:"seq(100)"`
+		Email string `auto:"seq(user%d@example.com)"`
+		Name  string `auto:"John"`
+		Admin bool   `auto:"false"`
+	}
+
+	factory := NewFactory[User](WithStrict()).
+		Trait("admin", func(u *User) { u.Admin = true }).
+		Trait("jane", func(u *User) { u.Name = "Jane" })
+
+	t.Run("success", func(t *testing.T) {
+		act := []User{
+			factory.Build(),
+			factory.Build(factory.With("admin")),
+			factory.Build(factory.With("admin", "jane"), func(u *User) { u.Email = "jane@example.com" }),
+		}
+		act = append(act, factory.BuildList(2, factory.With("jane"))...)
+
+		exp := []User{
+			{ID: 100, Email: "user1@example.com", Name: "John"},
+			{ID: 101, Email: "user2@example.com", Name: "John", Admin: true},
+			{ID: 102, Email: "jane@example.com", Name: "Jane", Admin: true},
+			{ID: 103, Email: "user4@example.com", Name: "Jane"},
+			{ID: 104, Email: "user5@example.com", Name: "Jane"},
+		}
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+
+	t.Run("independent-sequences", func(t *testing.T) {
+		act := NewFactory[User]().Build()
+
+		if act.ID != 100 || act.Email != "user1@example.com" {
+			t.Errorf("expected a fresh sequence, got: %+v", act)
+		}
+	})
+
+	t.Run("unknown-trait", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+
+		factory.With("missing")
+	})
+}
+
+func Test_NewCollections(t *testing.T) {
+	type Item struct {
+		ID      int           `auto:"rand(1,1000000)"`
+		Weight  float64       `auto:"rand(0.5,1.5)"`
+		Backoff time.Duration `auto:"rand(1s,5s)"`
+		Name    string        `auto:"item"`
+	}
+
+	t.Run("new-slice", func(t *testing.T) {
+		act := NewSlice[Item](100, WithSeed(42), WithStrict())
+
+		if len(act) != 100 {
+			t.Fatalf("expected 100 items, got %d", len(act))
+		}
+
+		ids := make(map[int]bool)
+		for _, item := range act {
+			ids[item.ID] = true
+
+			if item.Name != "item" || item.Weight < 0.5 || item.Weight > 1.5 || item.Backoff < time.Second || item.Backoff > 5*time.Second {
+				t.Errorf("unexpected item: %+v", item)
+			}
+		}
+
+		if len(ids) < 90 {
+			t.Errorf("expected elements to differ, got %d distinct ids", len(ids))
+		}
+
+		if again := NewSlice[Item](100, WithSeed(42)); !cmp.Equal(act, again) {
+			t.Error("expected the same seed to produce the same elements")
+		}
+	})
+
+	t.Run("new-slice-of-pointers", func(t *testing.T) {
+		act := NewSlice[*Item](2)
+
+		if act[0] == nil || act[1] == nil || act[0] == act[1] || act[0].Name != "item" {
+			t.Errorf("expected independent pointers, got: %v", act)
+		}
+	})
+
+	t.Run("new-map", func(t *testing.T) {
+		act := NewMap[string, Item]([]string{"a", "b"}, WithSeed(1))
+
+		if len(act) != 2 || act["a"].Name != "item" || act["b"].Name != "item" || act["a"].ID == act["b"].ID {
+			t.Errorf("unexpected map: %+v", act)
+		}
+	})
+
+	t.Run("full-range", func(t *testing.T) {
+		type Wide struct {
+			U64    uint64        `auto:"rand(0,18446744073709551615)"`
+			I64    int64         `auto:"rand(-9223372036854775808,9223372036854775807)"`
+			Wide   int64         `auto:"rand(-9223372036854775808,1)"`
+			Dur    time.Duration `auto:"rand(-2562047h47m16.854775808s,2562047h47m16.854775807s)"`
+			Narrow int8          `auto:"rand(-128,127)"`
+		}
+
+		act := NewSlice[Wide](50, WithSeed(7), WithStrict())
+
+		for _, w := range act {
+			if w.Wide > 1 {
+				t.Errorf("expected value up to 1, got %d", w.Wide)
+			}
+		}
+
+		if act[0] == act[1] {
+			t.Errorf("expected random values, got %+v", act[0])
+		}
+	})
+
+	t.Run("new-ptr", func(t *testing.T) {
+		act := NewPtr[Item]()
+
+		if act == nil || act.Name != "item" {
+			t.Errorf("unexpected pointer: %+v", act)
+		}
+	})
+}
+
+func Test_WithParallel(t *testing.T) {
+	type Node struct {
+		ID   int    `auto:"rand(1,1000000)"`
+		Name string `auto:"node"`
+		Next *Node
+	}
+
+	type Cluster struct {
+		Nodes   []*Node   `auto:"len(1000),repeat(struct)"`
+		Backups [300]Node `auto:"repeat(struct)"`
+		Ports   []int     `auto:"len(500),repeat(rand(1024,65535))"`
+	}
+
+	type Sequenced struct {
+		Users []struct {
+			ID int `auto:"seq(1)"`
+		} `auto:"len(200),repeat(struct)"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		act := New[Cluster](WithParallel(8), WithSeed(7))
+
+		if len(act.Nodes) != 1000 || len(act.Ports) != 500 {
+			t.Fatalf("unexpected lengths: %d nodes, %d ports", len(act.Nodes), len(act.Ports))
+		}
+
+		ids := make(map[int]bool)
+		for i, node := range act.Nodes {
+			if node == nil || node.Name != "node" {
+				t.Fatalf("unexpected node %d: %+v", i, node)
+			}
+			if i > 0 && node == act.Nodes[i-1] {
+				t.Fatalf("node %d shares its pointer with node %d", i, i-1)
+			}
+			ids[node.ID] = true
+		}
+
+		if len(ids) < 900 {
+			t.Errorf("expected independent random ids, got %d distinct", len(ids))
+		}
+
+		for _, node := range act.Backups {
+			if node.Name != "node" {
+				t.Fatalf("unexpected backup: %+v", node)
+			}
+		}
+
+		for i := 0; i < 3; i++ {
+			if again := New[Cluster](WithParallel(i+1), WithSeed(7)); !cmp.Equal(act, again) {
+				t.Fatal("expected deterministic output for the same seed")
+			}
+		}
+	})
+
+	t.Run("success-with-sequences", func(t *testing.T) {
+		act := New[Sequenced](WithParallel(8))
+
+		for i, user := range act.Users {
+			if user.ID != i+1 {
+				t.Fatalf("expected user %d to have id %d, got %d", i, i+1, user.ID)
+			}
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := NewContext[Cluster](ctx, WithParallel(8)); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	})
+}
+
+func Test_Cache(t *testing.T) {
+	type Small struct {
+		A string `auto:"a"`
+		B string `auto:"b"`
+	}
+
+	t.Run("stats", func(t *testing.T) {
+		cached := NewCache()
+		_ = New[Basic](WithCache(cached))
+		_ = New[Basic](WithCache(cached))
+
+		exp := CacheStats{Entries: 6, Hits: 6, Misses: 6}
+		if act := cached.Stats(); act != exp {
+			t.Errorf("expected %+v, got %+v", exp, act)
+		}
+
+		var decoded CacheStats
+		if err := json.Unmarshal([]byte(cached.String()), &decoded); err != nil || decoded != exp {
+			t.Errorf("expected JSON stats %+v, got %s (%v)", exp, cached.String(), err)
+		}
+	})
+
+	t.Run("delete-and-clear", func(t *testing.T) {
+		cached := NewCache()
+		_ = New[Basic](WithCache(cached))
+		_ = New[Small](WithCache(cached))
+
+		cached.Delete(reflect.TypeOf(&Basic{}))
+		if act := cached.Len(); act != 2 {
+			t.Errorf("expected 2 entries after delete, got %d", act)
+		}
+
+		cached.Clear()
+		if act := cached.Len(); act != 0 {
+			t.Errorf("expected 0 entries after clear, got %d", act)
+		}
+	})
+
+	t.Run("lru", func(t *testing.T) {
+		cached := NewLRUCache(4)
+		_ = New[Basic](WithCache(cached))
+
+		if stats := cached.Stats(); stats.Entries != 4 || stats.Evictions != 2 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+
+		act := New[Basic](WithCache(cached))
+		exp := Basic{Bool1: true, Bool3: true, String1: "abc", String2: "123"}
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+}
+
+type mapCache map[CacheKey]reflect.Value
+
+func (c mapCache) Get(key CacheKey) (reflect.Value, bool) {
+	val, ok := c[key]
+	return val, ok
+}
+
+func (c mapCache) Set(key CacheKey, val reflect.Value) {
+	c[key] = val
+}
+
+func Test_CacheInterface(t *testing.T) {
+	exp := Basic{Bool1: true, Bool3: true, String1: "abc", String2: "123"}
+
+	t.Run("custom", func(t *testing.T) {
+		cached := make(mapCache)
+
+		if err := Warm(cached, nil, &Basic{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(cached) != 6 {
+			t.Errorf("expected 6 warmed entries, got %d", len(cached))
+		}
+
+		key := CacheKey{Type: reflect.TypeOf(Basic{}), Field: "String1", Tag: defaultTag}
+		cached[key] = reflect.ValueOf("warmed")
+
+		act := New[Basic](WithCache(cached))
+		if act.String1 != "warmed" {
+			t.Errorf("expected value from custom cache, got [%s]", act.String1)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		type Profiled struct {
+			Level string `auto:"dev(debug),prod(info)"`
+		}
+
+		cached := make(mapCache)
+		if err := Warm(cached, []Option{WithProfile("prod")}, &Profiled{}); err != nil {
+			t.Fatal(err)
+		}
+
+		key := CacheKey{Type: reflect.TypeOf(Profiled{}), Field: "Level", Tag: defaultTag, Profile: "prod"}
+		if val, ok := cached[key]; !ok || val.String() != "info" {
+			t.Errorf("expected warmed prod entry, got %v", cached)
+		}
+	})
+
+	t.Run("nop", func(t *testing.T) {
+		act := New[Basic](WithCache(NopCache{}))
+
+		if !cmp.Equal(exp, act) {
+			t.Error(cmp.Diff(exp, act))
+		}
+	})
+}
+
+func Test_Filler(t *testing.T) {
+	type Tagged struct {
+		Name  string `default:"filler"`
+		Value int    `default:"rand(1,1000000)"`
+	}
+
+	preset := Options(WithTag("default"), WithStrict())
+
+	t.Run("success", func(t *testing.T) {
+		filler := NewFiller(preset, WithCache(NewCache()))
+
+		var (
+			wg   sync.WaitGroup
+			vals = make([]Tagged, 50)
+		)
+
+		for i := range vals {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				filler.MustSet(&vals[i])
+			}()
+		}
+		wg.Wait()
+
+		for _, v := range vals {
+			if v.Name != "filler" || v.Value < 1 {
+				t.Fatalf("unexpected value: %+v", v)
+			}
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		var a1, a2, b1, b2 Tagged
+
+		a, b := NewFiller(preset, WithSeed(3)), NewFiller(preset, WithSeed(3))
+		a.MustSet(&a1)
+		a.MustSet(&a2)
+		b.MustSet(&b1)
+		b.MustSet(&b2)
+
+		if a1 != b1 || a2 != b2 || a1 == a2 {
+			t.Errorf("expected deterministic distinct values, got %v %v %v %v", a1, a2, b1, b2)
+		}
+	})
+
+	t.Run("sequences", func(t *testing.T) {
+		type Numbered struct {
+			ID int `auto:"seq(1)"`
+		}
+
+		var v1, v2 Numbered
+		filler := NewFiller()
+		filler.MustSet(&v1)
+		filler.MustSet(&v2)
+
+		if v1.ID != 1 || v2.ID != 2 {
+			t.Errorf("expected sequence across calls, got %d and %d", v1.ID, v2.ID)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var v Tagged
+		if err := NewFiller(preset).SetContext(ctx, &v); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	})
+}
+
+func Test_Explain(t *testing.T) {
+	type Server struct {
+		Host string `auto:"localhost"`
+		Port *int   `auto:"8080"`
+	}
+
+	type Config struct {
+		Name    string
+		Server  Server        `auto:"struct"`
+		URL     string        `auto:"fmt(http://%s:%d, Server.Host, Server.Port)"`
+		Timeout time.Duration `auto:"value(5s)"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		v := Config{Name: "keep"}
+
+		act, err := Explain(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp := []FieldReport{
+			{Path: "Name", Type: "string", Setter: "stringSetter", Value: "keep", Skipped: true, Reason: "untagged"},
+			{Path: "Server.Host", Type: "string", Tag: "localhost", Commands: map[string]string{"value": "localhost"}, Setter: "stringSetter", Value: "localhost"},
+			{Path: "Server.Port", Type: "*int", Tag: "8080", Commands: map[string]string{"value": "8080"}, Setter: "int0Setter", Value: "8080"},
+			{Path: "Server", Type: "autostruct.Server", Tag: "struct", Commands: map[string]string{"value": "struct"}, Setter: "structSetter", Value: `{"Host":"localhost","Port":8080}`},
+			{Path: "URL", Type: "string", Tag: "fmt(http://%s:%d, Server.Host, Server.Port)", Commands: map[string]string{"fmt": "http://%s:%d, Server.Host, Server.Port"}, Setter: "stringSetter", Value: "http://localhost:8080"},
+			{Path: "Timeout", Type: "time.Duration", Tag: "value(5s)", Commands: map[string]string{"value": "5s"}, Setter: "durationSetter", Value: "5s"},
+		}
+
+		if !cmp.Equal(exp, act.Fields, cmpopts.IgnoreUnexported(FieldReport{})) {
+			t.Error(cmp.Diff(exp, act.Fields, cmpopts.IgnoreUnexported(FieldReport{})))
+		}
+
+		if v.Server.Host != "" || v.URL != "" {
+			t.Errorf("expected Explain not to modify its argument, got: %+v", v)
+		}
+
+		if table := act.String(); !strings.Contains(table, "Server.Port") || !strings.Contains(table, "untagged") {
+			t.Errorf("unexpected table:\n%s", table)
+		}
+
+		if _, err := json.Marshal(act); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		type Inner struct {
+			N int `auto:"5"`
+		}
+
+		type Outer struct {
+			Inner *Inner `auto:"struct"`
+			Port  *int   `auto:"8080"`
+		}
+
+		v := Outer{Inner: &Inner{}, Port: toPtr(0)}
+
+		act, err := Explain(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v.Inner.N != 0 || *v.Port != 0 {
+			t.Errorf("expected Explain not to modify pointed-to values, got: %d %d", v.Inner.N, *v.Port)
+		}
+
+		if f := act.Fields[len(act.Fields)-1]; f.Path != "Port" || f.Value != "8080" {
+			t.Errorf("unexpected field report: %+v", f)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		cached := NewCache()
+		_ = New[Config](WithCache(cached))
+
+		act, err := Explain(Config{}, WithCache(cached))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f := act.Fields[len(act.Fields)-1]; !f.Skipped || f.Reason != "cached" || f.Value != "5s" {
+			t.Errorf("expected cached field, got: %+v", f)
+		}
+	})
+}
+
+func Test_WithLogger(t *testing.T) {
+	type Config struct {
+		Host     string `auto:"localhost"`
+		Port     int    `auto:"lenght(5),value(8080)"`
+		Password string `auto:"secret,value(hunter2)"`
+		URL      string `auto:"fmt(http://%s:%d, Host, Port)"`
+		Comment  string
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	act := New[Config](WithLogger(logger))
+	if act.Password != "hunter2" {
+		t.Errorf("expected password to be set, got [%s]", act.Password)
+	}
+
+	type record struct {
+		Level  string
+		Msg    string
+		Path   string
+		Source string
+		Value  string
+		Error  string
+	}
+
+	var records []record
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+
+	exp := []record{
+		{Level: "DEBUG", Msg: "autostruct: field set", Path: "Host", Source: "value", Value: "localhost"},
+		{Level: "WARN", Msg: "autostruct: ignored tag commands", Path: "Port", Error: "unknown command [lenght]"},
+		{Level: "DEBUG", Msg: "autostruct: field set", Path: "Port", Source: "value", Value: "8080"},
+		{Level: "DEBUG", Msg: "autostruct: field set", Path: "Password", Source: "value", Value: "***"},
+		{Level: "DEBUG", Msg: "autostruct: field set", Path: "URL", Source: "fmt", Value: "http://localhost:8080"},
+	}
+
+	if !cmp.Equal(exp, records) {
+		t.Error(cmp.Diff(exp, records))
+	}
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("expected secret value to be redacted")
+	}
+}
+
+func Test_NestedSecrets(t *testing.T) {
+	type DB struct {
+		User     string `json:"user" auto:"admin"`
+		Password string `json:"password" auto:"secret,value(hunter2)"`
+	}
+
+	type Config struct {
+		DB       DB            `auto:"struct"`
+		Replicas []DB          `auto:"len(2),repeat(struct)"`
+		Shards   map[string]DB `auto:"value(a:struct)"`
+		Primary  *DB           `auto:"value(struct)"`
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	act := New[Config](WithLogger(logger), WithStrict())
+	if act.DB.Password != "hunter2" || act.Replicas[1].Password != "hunter2" || act.Primary.Password != "hunter2" {
+		t.Fatalf("expected secrets to be set, got %+v", act)
+	}
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("expected nested secrets to be redacted in logs, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `path=DB type=autostruct.DB source=value value="{\"user\":\"admin\",\"password\":\"***\"}"`) {
+		t.Errorf("expected parent to be logged with masked secret, got:\n%s", buf.String())
+	}
+
+	report, err := Explain(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]string)
+	for _, f := range report.Fields {
+		if strings.Contains(f.Value, "hunter2") {
+			t.Errorf("expected field [%s] to be redacted, got [%s]", f.Path, f.Value)
+		}
+		values[f.Path] = f.Value
+	}
+
+	exp := map[string]string{
+		"DB":          `{"user":"admin","password":"***"}`,
+		"DB.Password": "***",
+		"Replicas":    `[{"user":"admin","password":"***"},{"user":"admin","password":"***"}]`,
+		"Shards":      `{"a":{"user":"admin","password":"***"}}`,
+		"Primary":     `{"user":"admin","password":"***"}`,
+	}
+	for path, value := range exp {
+		if values[path] != value {
+			t.Errorf("expected [%s] to be [%s], got [%s]", path, value, values[path])
+		}
+	}
+}
+
+func Test_Secrets(t *testing.T) {
+	type Config struct {
+		Token    string           `auto:"secretfile(/run/secrets/token)"`
+		Password Redacted[string] `auto:"hunter2"`
+		Port     Redacted[int]    `auto:"8080"`
+	}
+
+	fsys := fstest.MapFS{
+		"run/secrets/token": {Data: []byte("s3cr3t\n")},
+	}
+
+	act, err := NewContext[Config](context.Background(), WithSecretFS(fsys), WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act.Token != "s3cr3t" || act.Password.Get() != "hunter2" || act.Port.Value != 8080 {
+		t.Errorf("unexpected values [%s] [%s] [%d]", act.Token, act.Password.Value, act.Port.Value)
+	}
+
+	if s := fmt.Sprintf("%v %+v %#v", act.Password, act.Password, act.Password); s != "*** *** ***" {
+		t.Errorf("expected password to be masked, got [%s]", s)
+	}
+
+	b, err := json.Marshal(act)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "hunter2") {
+		t.Errorf("expected password to be masked in JSON, got [%s]", b)
+	}
+
+	var dec Redacted[string]
+	if err := json.Unmarshal([]byte(`"open"`), &dec); err != nil || dec.Value != "open" {
+		t.Errorf("expected Redacted to unmarshal, got [%s] [%v]", dec.Value, err)
+	}
+
+	report, err := Explain(&Config{}, WithSecretFS(fsys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Fields {
+		if f.Value != redacted {
+			t.Errorf("expected field [%s] to be redacted, got [%s]", f.Path, f.Value)
+		}
+	}
+
+	_, err = NewContext[Config](context.Background(), WithSecretFS(fstest.MapFS{}))
+	if err == nil {
+		t.Error("expected error for missing secret file")
+	}
+}
+
+type watchedConfig struct {
+	Host    string `json:"host" auto:"localhost"`
+	Port    int    `json:"port" auto:"8080"`
+	Workers int    `json:"workers" auto:"4"`
+}
+
+func (c watchedConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+func Test_Watcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(data string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mod := time.Now().Add(-time.Hour)
+	write(`{"host":"example.com"}`, mod)
+
+	w, err := NewWatcher[watchedConfig](path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &watchedConfig{Host: "example.com", Port: 8080, Workers: 4}
+	if !cmp.Equal(exp, w.Load()) {
+		t.Error(cmp.Diff(exp, w.Load()))
+	}
+
+	changes := make(chan []string, 1)
+	w.OnChange(func(_, _ *watchedConfig, paths []string) {
+		changes <- paths
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Watch(ctx)
+
+	write(`{"host":"example.com","port":9090}`, mod.Add(time.Minute))
+	select {
+	case paths := <-changes:
+		if !cmp.Equal([]string{"Port"}, paths) {
+			t.Error(cmp.Diff([]string{"Port"}, paths))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected change callback")
+	}
+	cancel()
+
+	if w.Load().Port != 9090 || w.Load().Workers != 4 {
+		t.Errorf("unexpected value after reload %+v", *w.Load())
+	}
+
+	write(`{"port":-1}`, mod.Add(2*time.Minute))
+	if err := w.Reload(); err == nil || w.Err() == nil {
+		t.Error("expected validation error")
+	}
+	if w.Load().Port != 9090 {
+		t.Errorf("expected previous value to be kept, got %+v", *w.Load())
+	}
+
+	write(`{`, mod.Add(3*time.Minute))
+	if err := w.Reload(); err == nil {
+		t.Error("expected decoding error")
+	}
+
+	if _, err := NewWatcher[watchedConfig](filepath.Join(t.TempDir(), "missing.json"), time.Second); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	if _, err := NewWatcher[watchedConfig](path, 0); err == nil {
+		t.Error("expected error for non-positive interval")
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	type Server struct {
+		Host string `auto:"localhost"`
+		Port int    `auto:"8080"`
+	}
+
+	type Config struct {
+		Name     string            `auto:"app"`
+		Server   *Server           `auto:"value(struct)"`
+		Tags     []string          `auto:"json([\"a\"])"`
+		Timeout  time.Duration     `auto:"5s"`
+		Password string            `auto:"secret,value(changeme)"`
+		Token    Redacted[string]  `auto:"default"`
+		Labels   map[string]string `auto:"json({\"env\":\"dev\"})"`
+		Comment  string
+	}
+
+	cfg := New[Config]()
+	if changes, err := Diff(cfg); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	cfg.Server.Port = 9090
+	cfg.Tags = append(cfg.Tags, "b")
+	cfg.Timeout = time.Minute
+	cfg.Password = "hunter2"
+	cfg.Token.Value = "s3cr3t"
+	cfg.Comment = "hello"
+
+	exp := []Change{
+		{Path: "Server.Port", Old: "8080", New: "9090"},
+		{Path: "Tags", Old: `["a"]`, New: `["a","b"]`},
+		{Path: "Timeout", Old: "5s", New: "1m0s"},
+		{Path: "Password", Old: "***", New: "***"},
+		{Path: "Token", Old: "***", New: "***"},
+		{Path: "Comment", Old: "", New: "hello"},
+	}
+
+	if act, err := Diff(&cfg); err != nil || !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+
+	type Profiled struct {
+		Port int `auto:"value(8080),prod(443)"`
+	}
+
+	if act, err := Diff(Profiled{Port: 443}, WithProfile("prod"), WithStrict()); err != nil || len(act) != 0 {
+		t.Errorf("expected no changes under profile, got %v", act)
+	}
+
+	if act, err := Diff(Profiled{Port: 8080}, WithProfiles("prod"), WithStrict()); err != nil || len(act) != 0 {
+		t.Errorf("expected no changes without profile, got %v", act)
+	}
+
+	type Secret struct {
+		Token string `auto:"secretfile(/run/secrets/missing)"`
+	}
+
+	if _, err := Diff(Secret{}, WithSecretFS(fstest.MapFS{})); err == nil {
+		t.Error("expected error for missing secret file")
+	}
+
+	type Volatile struct {
+		Created time.Time `auto:"now"`
+		Expires time.Time `auto:"ref(Created)"`
+		ID      int       `auto:"rand(1,1000000)"`
+		Order   int       `auto:"seq(1)"`
+		Name    string    `auto:"app"`
+	}
+
+	vol := New[Volatile]()
+	vol.Name = "changed"
+
+	if act, err := Diff(&vol); err != nil || !cmp.Equal([]Change{{Path: "Name", Old: "app", New: "changed"}}, act) {
+		t.Errorf("expected volatile defaults to be skipped, got %v (%v)", act, err)
+	}
+}
+
+func Test_UnmarshalJSON(t *testing.T) {
+	type Backend struct {
+		Host   string `json:"host" auto:"localhost"`
+		Port   int    `json:"port" auto:"8080"`
+		Weight int    `json:"weight,string" auto:"1"`
+	}
+
+	type Base struct {
+		Region string `json:"region" auto:"eu"`
+	}
+
+	type Config struct {
+		Base
+		Name     string              `json:"name" auto:"app"`
+		Backends []Backend           `json:"backends"`
+		Primary  *Backend            `json:"primary"`
+		Fixed    [2]Backend          `json:"fixed"`
+		Routes   map[string]*Backend `json:"routes"`
+		Timeout  time.Duration       `json:"timeout" auto:"5s"`
+		Ignored  string              `json:"-" auto:"kept"`
+	}
+
+	data := `{
+		"region": "us",
+		"backends": [{"host": "a"}, {"port": 9000, "weight": "3"}],
+		"primary": {"host": "p"},
+		"fixed": [{"port": 1}],
+		"routes": {"api": {"host": "r"}},
+		"NAME": "svc",
+		"Ignored": "nope"
+	}`
+
+	var act Config
+	if err := UnmarshalJSON([]byte(data), &act, WithStrict()); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := Config{
+		Base: Base{Region: "us"},
+		Name: "svc",
+		Backends: []Backend{
+			{Host: "a", Port: 8080, Weight: 1},
+			{Host: "localhost", Port: 9000, Weight: 3},
+		},
+		Primary: &Backend{Host: "p", Port: 8080, Weight: 1},
+		Fixed:   [2]Backend{{Host: "localhost", Port: 1, Weight: 1}, {}},
+		Routes:  map[string]*Backend{"api": {Host: "r", Port: 8080, Weight: 1}},
+		Timeout: 5 * time.Second,
+		Ignored: "kept",
+	}
+
+	if !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+
+	var fe *FieldError
+	err := UnmarshalJSON([]byte(`{"backends": [{}, {"port": "x"}]}`), &act)
+	if !errors.As(err, &fe) || fe.Path != "Backends.1.Port" {
+		t.Errorf("expected field error for [Backends.1.Port], got [%v]", err)
+	}
+
+	if err := UnmarshalJSON([]byte(`{}`), act); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
+
+func Test_Defaulted(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name" auto:"item"`
+		Count int    `json:"count" auto:"1"`
+	}
+
+	type Request struct {
+		Items []Defaulted[Item] `json:"items"`
+	}
+
+	var req Request
+	if err := json.Unmarshal([]byte(`{"items":[{"name":"a"},{"count":5}]}`), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []Defaulted[Item]{{Value: Item{Name: "a", Count: 1}}, {Value: Item{Name: "item", Count: 5}}}
+	if !cmp.Equal(exp, req.Items) {
+		t.Error(cmp.Diff(exp, req.Items))
+	}
+
+	b, err := json.Marshal(req.Items[0])
+	if err != nil || string(b) != `{"name":"a","count":1}` {
+		t.Errorf("unexpected marshal [%s] [%v]", b, err)
+	}
+
+	var d Defaulted[time.Duration]
+	if err := d.UnmarshalText([]byte("1m")); err != nil || d.Get() != time.Minute {
+		t.Errorf("unexpected text unmarshal [%s] [%v]", d.Get(), err)
+	}
+}
+
+func Test_Walk(t *testing.T) {
+	type Inner struct {
+		Since time.Time `auto:"query(since),layout(DateOnly)"`
+	}
+
+	type Outer struct {
+		Limit  int    `auto:"query(limit),value(10)"`
+		Inner  *Inner `auto:"value(struct)"`
+		Plain  string
+		hidden string
+	}
+
+	v := New[Outer](WithStrict())
+	if v.Limit != 10 || v.Inner == nil {
+		t.Fatalf("unexpected defaults %+v", v)
+	}
+
+	var paths []string
+	err := Walk(&v, func(f Field) error {
+		paths = append(paths, f.Path)
+		if name, ok := f.Commands["query"]; ok && name == "since" {
+			return SetString(f.Value, f.Tag, "2024-03-01")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := []string{"Limit", "Inner", "Inner.Since", "Plain"}; !cmp.Equal(exp, paths) {
+		t.Error(cmp.Diff(exp, paths))
+	}
+
+	if exp := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !v.Inner.Since.Equal(exp) {
+		t.Errorf("expected [%s], got [%s]", exp, v.Inner.Since)
+	}
+}
+
+func Test_SQLNull(t *testing.T) {
+	type Row struct {
+		Name    sql.NullString    `auto:"gopher"`
+		Count   sql.NullInt64     `auto:"42"`
+		Created sql.NullTime      `auto:"2024-03-01T00:00:00Z"`
+		Score   sql.Null[float64] `auto:"1.5"`
+		Column  sql.NullString    `auto:"db(name)"`
+	}
+
+	exp := Row{
+		Name:    sql.NullString{String: "gopher", Valid: true},
+		Count:   sql.NullInt64{Int64: 42, Valid: true},
+		Created: sql.NullTime{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		Score:   sql.Null[float64]{V: 1.5, Valid: true},
+	}
+
+	if act := New[Row](WithStrict()); !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+}
+
+func Test_Atomics(t *testing.T) {
+	type Limits struct {
+		Max int `auto:"10"`
+	}
+
+	type Tuning struct {
+		Workers atomic.Int32                  `auto:"8"`
+		Buffer  atomic.Int64                  `auto:"bytes(1KiB)"`
+		Retries atomic.Uint32                 `auto:"3"`
+		Seen    atomic.Uint64                 `auto:"0x10"`
+		Addr    atomic.Uintptr                `auto:"1"`
+		Enabled atomic.Bool                   `auto:"true"`
+		Mode    atomic.Value                  `auto:"fast"`
+		Limits  atomic.Pointer[Limits]        `auto:"value(struct)"`
+		Timeout atomic.Pointer[time.Duration] `auto:"5s"`
+	}
+
+	act := NewPtr[Tuning](WithStrict())
+
+	if act.Workers.Load() != 8 || act.Buffer.Load() != 1024 || act.Retries.Load() != 3 ||
+		act.Seen.Load() != 16 || act.Addr.Load() != 1 || !act.Enabled.Load() {
+		t.Errorf("unexpected atomic values")
+	}
+
+	if mode, _ := act.Mode.Load().(string); mode != "fast" {
+		t.Errorf("expected mode [fast], got [%v]", act.Mode.Load())
+	}
+
+	if l := act.Limits.Load(); l == nil || l.Max != 10 {
+		t.Errorf("expected limits to be set, got [%v]", l)
+	}
+
+	if d := act.Timeout.Load(); d == nil || *d != 5*time.Second {
+		t.Errorf("expected timeout to be set, got [%v]", d)
+	}
+}
+
+func Test_StdTypes(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL        `auto:"https://example.com/api?v=1"`
+		Proxy    *url.URL       `auto:"http://proxy:3128"`
+		IP       net.IP         `auto:"10.0.0.1"`
+		Addr     netip.Addr     `auto:"::1"`
+		Prefix   netip.Prefix   `auto:"10.0.0.0/8"`
+		Listen   netip.AddrPort `auto:"127.0.0.1:8080"`
+		Pattern  *regexp.Regexp `auto:"^[a-z]+$"`
+		Big      *big.Int       `auto:"0x1fffffffffffffffff"`
+		Float    big.Float      `auto:"1.5"`
+		Ratio    *big.Rat       `auto:"3/4"`
+		Location *time.Location `auto:"Europe/Berlin"`
+		Month    time.Month     `auto:"mar"`
+		Weekday  time.Weekday   `auto:"Friday"`
+		Day      time.Weekday   `auto:"1"`
+		Admin    mail.Address   `auto:"Admin <admin@example.com>"`
+		Support  *mail.Address  `auto:"support@example.com"`
+	}
+
+	act := New[Config](WithStrict())
+
+	if act.Endpoint.Host != "example.com" || act.Endpoint.Query().Get("v") != "1" || act.Proxy.Port() != "3128" {
+		t.Errorf("unexpected urls [%s] [%s]", act.Endpoint.String(), act.Proxy)
+	}
+
+	if !act.IP.Equal(net.IPv4(10, 0, 0, 1)) || act.Addr != netip.IPv6Loopback() ||
+		act.Prefix.String() != "10.0.0.0/8" || act.Listen.Port() != 8080 {
+		t.Errorf("unexpected addresses [%s] [%s] [%s] [%s]", act.IP, act.Addr, act.Prefix, act.Listen)
+	}
+
+	if !act.Pattern.MatchString("abc") || act.Pattern.MatchString("ABC") {
+		t.Errorf("unexpected pattern [%s]", act.Pattern)
+	}
+
+	if act.Big.String() != "590295810358705651711" || act.Float.String() != "1.5" || act.Ratio.String() != "3/4" {
+		t.Errorf("unexpected big numbers [%s] [%s] [%s]", act.Big, act.Float.String(), act.Ratio)
+	}
+
+	if act.Location.String() != "Europe/Berlin" || act.Month != time.March || act.Weekday != time.Friday || act.Day != time.Monday {
+		t.Errorf("unexpected time values [%s] [%s] [%s] [%s]", act.Location, act.Month, act.Weekday, act.Day)
+	}
+
+	if act.Admin.Name != "Admin" || act.Admin.Address != "admin@example.com" || act.Support.Address != "support@example.com" {
+		t.Errorf("unexpected addresses [%s] [%s]", act.Admin.String(), act.Support)
+	}
+
+	type Invalid struct {
+		Month time.Month `auto:"13"`
+	}
+
+	var fe *FieldError
+	if err := Set(&Invalid{}); !errors.As(err, &fe) || fe.Path != "Month" {
+		t.Errorf("expected field error for [Month], got [%v]", err)
+	}
+}
+
+type backoff func(attempt int) time.Duration
+
+func Test_Funcs(t *testing.T) {
+	RegisterFunc("exponential", func(attempt int) time.Duration {
+		return time.Duration(1<<attempt) * time.Second
+	})
+
+	type Client struct {
+		Retry    backoff                   `auto:"func(exponential)"`
+		Delay    func(int) time.Duration   `auto:"exponential"`
+		OnError  func(error)               `auto:"func(noop)"`
+		Validate func(string) (int, error) `auto:"noop"`
+		Profiled func(int) time.Duration   `auto:"func(noop),prod(exponential)"`
+	}
+
+	act := New[Client](WithStrict(), WithProfiles("prod"))
+
+	if act.Retry(3) != 8*time.Second || act.Delay(1) != 2*time.Second {
+		t.Errorf("unexpected registered functions [%s] [%s]", act.Retry(3), act.Delay(1))
+	}
+
+	act.OnError(errors.New("ignored"))
+	if n, err := act.Validate("x"); n != 0 || err != nil {
+		t.Errorf("expected zero values from noop, got [%d] [%v]", n, err)
+	}
+
+	if act.Profiled(2) != 0 {
+		t.Errorf("expected noop without profile")
+	}
+
+	if prod := New[Client](WithProfiles("prod"), WithProfile("prod")); prod.Profiled(2) != 4*time.Second {
+		t.Errorf("expected registered function under profile, got [%s]", prod.Profiled(2))
+	}
+
+	type Invalid struct {
+		Unknown func() `auto:"func(missing)"`
+	}
+
+	if err := Set(&Invalid{}); err == nil || !strings.Contains(err.Error(), "unknown function [missing]") {
+		t.Errorf("expected unknown function error, got [%v]", err)
+	}
+
+	type Mismatch struct {
+		Wrong func() `auto:"func(exponential)"`
+	}
+
+	if err := Set(&Mismatch{}); err == nil || !strings.Contains(err.Error(), "is not assignable") {
+		t.Errorf("expected assignability error, got [%v]", err)
+	}
+}
+
+func Test_Channels(t *testing.T) {
+	type Job struct {
+		Name     string `auto:"job"`
+		Priority int    `auto:"1"`
+	}
+
+	type Pool struct {
+		Unbuffered chan int           `auto:"chan"`
+		Tokens     chan struct{}      `auto:"chan(3),repeat(struct)"`
+		Ports      <-chan int         `auto:"chan(5),items(80, 443, 8080)"`
+		Names      chan<- string      `auto:"chan(2),items(a,b)"`
+		Jobs       chan *Job          `auto:"chan(4),len(2),repeat(struct)"`
+		Timeouts   chan time.Duration `auto:"chan(1),items(5s)"`
+	}
+
+	act := New[Pool](WithStrict())
+
+	if act.Unbuffered == nil || cap(act.Unbuffered) != 0 {
+		t.Errorf("expected unbuffered channel, got [%v]", act.Unbuffered)
+	}
+
+	if len(act.Tokens) != 3 || cap(act.Tokens) != 3 {
+		t.Errorf("expected 3 tokens, got [%d/%d]", len(act.Tokens), cap(act.Tokens))
+	}
+
+	var ports []int
+	for len(act.Ports) > 0 {
+		ports = append(ports, <-act.Ports)
+	}
+	if exp := []int{80, 443, 8080}; !cmp.Equal(exp, ports) || cap(act.Ports) != 5 {
+		t.Error(cmp.Diff(exp, ports))
+	}
+
+	if len(act.Names) != 2 || cap(act.Names) != 2 {
+		t.Errorf("expected 2 names, got [%d/%d]", len(act.Names), cap(act.Names))
+	}
+
+	if len(act.Jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got [%d]", len(act.Jobs))
+	}
+	if job := <-act.Jobs; job.Name != "job" || job.Priority != 1 {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	if d := <-act.Timeouts; d != 5*time.Second {
+		t.Errorf("expected [5s], got [%s]", d)
+	}
+
+	cache := NewCache()
+	first, second := New[Pool](WithCache(cache)), New[Pool](WithCache(cache))
+	if first.Tokens == second.Tokens {
+		t.Error("expected channels not to be shared through the cache")
+	}
+
+	type Overflow struct {
+		Tokens chan int `auto:"chan(1),items(1,2)"`
+	}
+
+	if err := Set(&Overflow{}); err == nil || !strings.Contains(err.Error(), "exceeds channel buffer") {
+		t.Errorf("expected overflow error, got [%v]", err)
+	}
+}
+
+func Test_PlanBound(t *testing.T) {
+	type Tenant struct {
+		Name string `auto:"default"`
+	}
+
+	ClearPlans()
+	for i := 0; i < maxPlans+10; i++ {
+		if act := New[Tenant](WithProfile(strconv.Itoa(i))); act.Name != "default" {
+			t.Fatalf("unexpected value [%s]", act.Name)
+		}
+	}
+
+	if n := planCount.Load(); n > maxPlans {
+		t.Errorf("expected at most %d plans, got %d", maxPlans, n)
+	}
+
+	ClearPlans()
+	if n := planCount.Load(); n != 0 {
+		t.Errorf("expected no plans after clear, got %d", n)
+	}
+}
+
+func toPtr[T any](v T) *T {
+	return &v
+}
+
 func Benchmark_NotCached(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = New[Test]()
@@ -453,6 +2196,22 @@ func Benchmark_Cached(b *testing.B) {
 	}
 }
 
+func Benchmark_NewSlice(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = NewSlice[Test](10000, WithCache(NewCache()))
+	}
+}
+
+func Benchmark_Parallel(b *testing.B) {
+	type Bulk struct {
+		Items []Test `auto:"len(10000),repeat(struct)"`
+	}
+
+	for i := 0; i < b.N; i++ {
+		_ = New[Bulk](WithParallel(8))
+	}
+}
+
 func Benchmark_DeepCopy(b *testing.B) {
 	cached := NewCache()
 
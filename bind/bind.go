@@ -0,0 +1,134 @@
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+
+	autostruct "github.com/arsmn/auto-struct"
+)
+
+const maxMemory = 32 << 20
+
+var sources = []string{"path", "query", "header", "form"}
+
+type Error struct {
+	Path   string
+	Source string
+	Name   string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", e.Source, e.Err)
+	}
+
+	return fmt.Sprintf("field [%s]: %s [%s]: %s", e.Path, e.Source, e.Name, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func BindRequest(r *http.Request, v any, opts ...autostruct.Option) error {
+	if err := autostruct.SetContext(r.Context(), v, opts...); err != nil {
+		return err
+	}
+
+	b := &binder{r: r}
+
+	return autostruct.Walk(v, func(f autostruct.Field) error {
+		for _, source := range sources {
+			name, ok := f.Commands[source]
+			if !ok {
+				continue
+			}
+
+			values, err := b.lookup(source, name)
+			if err != nil {
+				return &Error{Source: source, Err: err}
+			}
+			if len(values) == 0 {
+				continue
+			}
+
+			if err := set(f, values, opts); err != nil {
+				return &Error{Path: f.Path, Source: source, Name: name, Err: err}
+			}
+			return nil
+		}
+
+		return nil
+	}, opts...)
+}
+
+func Status(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
+
+type binder struct {
+	r      *http.Request
+	parsed bool
+}
+
+func (b *binder) lookup(source, name string) ([]string, error) {
+	switch source {
+	case "path":
+		if value := b.r.PathValue(name); value != "" {
+			return []string{value}, nil
+		}
+	case "query":
+		return b.r.URL.Query()[name], nil
+	case "header":
+		return b.r.Header.Values(name), nil
+	case "form":
+		if err := b.parseForm(); err != nil {
+			return nil, err
+		}
+		return b.r.PostForm[name], nil
+	}
+
+	return nil, nil
+}
+
+func (b *binder) parseForm() error {
+	if b.parsed {
+		return nil
+	}
+	b.parsed = true
+
+	if ct, _, _ := mime.ParseMediaType(b.r.Header.Get("Content-Type")); ct == "multipart/form-data" {
+		return b.r.ParseMultipartForm(maxMemory)
+	}
+
+	return b.r.ParseForm()
+}
+
+func set(f autostruct.Field, values []string, opts []autostruct.Option) error {
+	v := f.Value
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return autostruct.SetString(v, f.Tag, values[0], opts...)
+	}
+
+	s := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := autostruct.SetString(s.Index(i), f.Tag, value, opts...); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package bind
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Page struct {
+	Limit  int `auto:"query(limit),value(20)"`
+	Offset int `auto:"query(offset)"`
+}
+
+type ListRequest struct {
+	ID      int           `auto:"path(id)"`
+	Page    Page          `auto:"value(struct)"`
+	Tags    []string      `auto:"query(tag)"`
+	Since   time.Time     `auto:"query(since),layout(DateOnly)"`
+	Timeout time.Duration `auto:"header(X-Timeout),value(5s)"`
+	Debug   bool          `auto:"header(X-Debug),query(debug),value(false)"`
+	Name    string        `auto:"form(name),value(anonymous)"`
+	Ignored string        `auto:"kept"`
+	Token   []byte        `auto:"query(token)"`
+	Raw     *[]byte       `auto:"header(X-Raw)"`
+}
+
+func Test_BindRequest(t *testing.T) {
+	var (
+		act ListRequest
+		err error
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		err = BindRequest(r, &act)
+	})
+
+	form := url.Values{"name": {"gopher"}}
+	r := httptest.NewRequest(http.MethodPost, "/items/42?limit=5&tag=a&tag=b&since=2024-03-01&debug=true&token=hello", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Timeout", "1m")
+	r.Header.Set("X-Raw", "raw")
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := ListRequest{
+		ID:      42,
+		Page:    Page{Limit: 5},
+		Tags:    []string{"a", "b"},
+		Since:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Timeout: time.Minute,
+		Debug:   true,
+		Name:    "gopher",
+		Ignored: "kept",
+		Token:   []byte("hello"),
+		Raw:     toPtr([]byte("raw")),
+	}
+
+	if !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+}
+
+func Test_BindRequestDefaults(t *testing.T) {
+	var act ListRequest
+	if err := BindRequest(httptest.NewRequest(http.MethodGet, "/", nil), &act); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := ListRequest{Page: Page{Limit: 20}, Timeout: 5 * time.Second, Name: "anonymous", Ignored: "kept"}
+	if !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+}
+
+func Test_BindRequestError(t *testing.T) {
+	var act ListRequest
+	err := BindRequest(httptest.NewRequest(http.MethodGet, "/?offset=abc", nil), &act)
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected bind error, got [%v]", err)
+	}
+
+	if e.Path != "Page.Offset" || e.Source != "query" || e.Name != "offset" {
+		t.Errorf("unexpected error fields %+v", e)
+	}
+
+	if Status(err) != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", Status(err))
+	}
+}
+
+func toPtr[T any](v T) *T {
+	return &v
+}
@@ -1,30 +1,190 @@
 package autostruct
 
 import (
+	"container/list"
+	"encoding/json"
+	"log/slog"
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
-type cache struct {
-	lock sync.RWMutex
-	vals map[string]reflect.Value
+type Cache interface {
+	Get(key CacheKey) (reflect.Value, bool)
+	Set(key CacheKey, val reflect.Value)
 }
 
-func NewCache() *cache {
-	return &cache{
-		vals: make(map[string]reflect.Value),
+type CacheKey struct {
+	Type    reflect.Type
+	Field   string
+	Tag     string
+	Profile string
+}
+
+type NopCache struct{}
+
+func (NopCache) Get(CacheKey) (reflect.Value, bool) {
+	return reflect.Value{}, false
+}
+
+func (NopCache) Set(CacheKey, reflect.Value) {}
+
+// Warm fills the types of vs into cache using opts, so a profile or tag set
+// there is warmed under the same keys later fills look up.
+func Warm(cache Cache, opts []Option, vs ...any) error {
+	opts = append(opts[:len(opts):len(opts)], WithCache(cache))
+
+	for _, v := range vs {
+		typ := reflect.TypeOf(v)
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if err := Set(reflect.New(typ).Interface(), opts...); err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+type cacheEntry struct {
+	key  CacheKey
+	val  reflect.Value
+	elem *list.Element
 }
 
-func (c *cache) get(key string) (reflect.Value, bool) {
+type MemoryCache struct {
+	lock       sync.RWMutex
+	vals       map[CacheKey]*cacheEntry
+	lru        *list.List
+	maxEntries int
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type CacheStats struct {
+	Entries   int    `json:"entries"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+func (s CacheStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("entries", s.Entries),
+		slog.Uint64("hits", s.Hits),
+		slog.Uint64("misses", s.Misses),
+		slog.Uint64("evictions", s.Evictions),
+	)
+}
+
+func NewCache() *MemoryCache {
+	return NewLRUCache(0)
+}
+
+func NewLRUCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		vals:       make(map[CacheKey]*cacheEntry),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *MemoryCache) Get(key CacheKey) (reflect.Value, bool) {
+	if c.maxEntries <= 0 {
+		c.lock.RLock()
+		entry, ok := c.vals[key]
+		c.lock.RUnlock()
+		return c.record(entry, ok)
+	}
+
+	c.lock.Lock()
+	entry, ok := c.vals[key]
+	if ok {
+		c.lru.MoveToFront(entry.elem)
+	}
+	c.lock.Unlock()
+
+	return c.record(entry, ok)
+}
+
+func (c *MemoryCache) record(entry *cacheEntry, ok bool) (reflect.Value, bool) {
+	if !ok {
+		c.misses.Add(1)
+		return reflect.Value{}, false
+	}
+
+	c.hits.Add(1)
+	return entry.val, true
+}
+
+func (c *MemoryCache) Set(key CacheKey, val reflect.Value) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.vals[key]; ok {
+		entry.val = val
+		c.lru.MoveToFront(entry.elem)
+		return
+	}
+
+	entry := &cacheEntry{key: key, val: val}
+	entry.elem = c.lru.PushFront(entry)
+	c.vals[key] = entry
+
+	for c.maxEntries > 0 && len(c.vals) > c.maxEntries {
+		c.remove(c.lru.Back().Value.(*cacheEntry))
+		c.evictions.Add(1)
+	}
+}
+
+func (c *MemoryCache) remove(entry *cacheEntry) {
+	c.lru.Remove(entry.elem)
+	delete(c.vals, entry.key)
+}
+
+func (c *MemoryCache) Len() int {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	val, ok := c.vals[key]
-	return val, ok
+	return len(c.vals)
 }
 
-func (c *cache) set(key string, val reflect.Value) {
+func (c *MemoryCache) Clear() {
 	c.lock.Lock()
-	c.vals[key] = val
+	c.vals = make(map[CacheKey]*cacheEntry)
+	c.lru.Init()
 	c.lock.Unlock()
 }
+
+func (c *MemoryCache) Delete(typ reflect.Type) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, entry := range c.vals {
+		if key.Type == typ {
+			c.remove(entry)
+		}
+	}
+}
+
+func (c *MemoryCache) Stats() CacheStats {
+	return CacheStats{
+		Entries:   c.Len(),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// String renders the stats as JSON, so the cache can be published with expvar.Publish.
+func (c *MemoryCache) String() string {
+	b, _ := json.Marshal(c.Stats())
+	return string(b)
+}
@@ -1,14 +1,64 @@
 package autostruct
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
-var rx = regexp.MustCompile(`(\w+)\((.*?\{.*?\}.*?|[^()]+)\)`)
+var (
+	rx = regexp.MustCompile(`(\w+)\(("(?:[^"\\]|\\.)*"|.*?\{.*?\}.*?|(?:[^()]|\([^()]*\))+)\)`)
+
+	knownCommands = map[string]struct{}{
+		"value":      {},
+		"json":       {},
+		"repeat":     {},
+		"rune":       {},
+		"byte":       {},
+		"chan":       {},
+		"len":        {},
+		"cap":        {},
+		"layout":     {},
+		"unix":       {},
+		"unixmilli":  {},
+		"tz":         {},
+		"bytes":      {},
+		"si":         {},
+		"percent":    {},
+		"quote":      {},
+		"file":       {},
+		"base64":     {},
+		"hex":        {},
+		"template":   {},
+		"ref":        {},
+		"expr":       {},
+		"fmt":        {},
+		"seq":        {},
+		"rand":       {},
+		"secret":     {},
+		"secretfile": {},
+		"query":      {},
+		"header":     {},
+		"path":       {},
+		"form":       {},
+		"db":         {},
+		"func":       {},
+		"items":      {},
+	}
+	flagCommands = map[string]struct{}{
+		"secret": {},
+	}
+	bindCommands      = []string{"path", "query", "header", "form", "db"}
+	numericCommands   = []string{"len", "cap", "chan", "unix", "unixmilli"}
+	exclusiveCommands = []string{"value", "json", "repeat", "rune", "byte", "unix", "unixmilli", "bytes", "si", "percent", "quote", "file", "base64", "hex", "template", "ref", "expr", "fmt", "seq", "rand", "secretfile", "func", "items"}
+)
 
 type command struct {
-	list map[string]string
+	list  map[string]string
+	dups  []string
+	extra []string
 }
 
 func (c command) isCMD(cmd string) bool {
@@ -48,6 +98,69 @@ func (c command) layout() string {
 	return c.list["layout"]
 }
 
+func (c command) isUnix() bool {
+	return c.isCMD("unix")
+}
+
+func (c command) unix() string {
+	return c.cmd("unix")
+}
+
+func (c command) isUnixMilli() bool {
+	return c.isCMD("unixmilli")
+}
+
+func (c command) unixMilli() string {
+	return c.cmd("unixmilli")
+}
+
+func (c command) tz() string {
+	return c.list["tz"]
+}
+
+func (c command) unit() (string, string, bool) {
+	for name := range unitCommands {
+		if c.isCMD(name) {
+			return name, c.cmd(name), true
+		}
+	}
+
+	return "", "", false
+}
+
+func (c command) isReference() bool {
+	return c.isCMD("ref") || c.isCMD("expr") || c.isCMD("fmt") || c.isCMD("template")
+}
+
+func (c command) isSecret() bool {
+	return c.isCMD("secret") || c.isCMD("secretfile")
+}
+
+func (c command) isBindOnly() bool {
+	bound := false
+	for _, name := range bindCommands {
+		bound = bound || c.isCMD(name)
+	}
+
+	for _, name := range exclusiveCommands {
+		if c.isCMD(name) {
+			return false
+		}
+	}
+
+	return bound
+}
+
+func (c command) source() string {
+	for _, name := range exclusiveCommands {
+		if c.isCMD(name) {
+			return name
+		}
+	}
+
+	return "value"
+}
+
 func (c command) isValueStruct() bool {
 	return c.value() == "struct"
 }
@@ -103,19 +216,138 @@ func (c command) buffer() int {
 	return i
 }
 
+func (c command) validate(profiles map[string]struct{}) error {
+	if len(c.extra) > 0 {
+		return fmt.Errorf("unexpected text outside of commands [%s]", strings.Join(c.extra, ","))
+	}
+
+	if len(c.dups) > 0 {
+		return fmt.Errorf("duplicate command [%s]", c.dups[0])
+	}
+
+	names := make([]string, 0, len(c.list))
+	for name := range c.list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := knownCommands[name]; ok {
+			continue
+		}
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("unknown command [%s]", name)
+		}
+	}
+
+	for _, name := range numericCommands {
+		if arg, ok := c.list[name]; ok && arg != "" {
+			if _, err := strconv.Atoi(arg); err != nil {
+				return fmt.Errorf("command [%s] requires a number, got [%s]", name, arg)
+			}
+		}
+	}
+
+	var found string
+	for _, name := range exclusiveCommands {
+		if !c.isCMD(name) {
+			continue
+		}
+		if found != "" {
+			return fmt.Errorf("conflicting commands [%s] and [%s]", found, name)
+		}
+		found = name
+	}
+
+	return nil
+}
+
+// isProfileOnly reports a tag whose values all belong to profiles that are
+// not selected, so there is nothing to set.
+func (c command) isProfileOnly() bool {
+	profiled := false
+	for name := range c.list {
+		if _, ok := knownCommands[name]; !ok {
+			profiled = true
+		}
+	}
+
+	if !profiled || c.isCMD("len") || c.isCMD("cap") || c.isCMD("chan") {
+		return false
+	}
+
+	for _, name := range exclusiveCommands {
+		if c.isCMD(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c command) withProfile(profile string) command {
+	arg, ok := c.list[profile]
+	if !ok {
+		return c
+	}
+
+	target := "value"
+	for _, name := range exclusiveCommands {
+		if c.isCMD(name) {
+			target = name
+			break
+		}
+	}
+
+	list := make(map[string]string, len(c.list))
+	for k, v := range c.list {
+		if k != profile {
+			list[k] = v
+		}
+	}
+	list[target] = arg
+
+	return command{list: list, dups: c.dups, extra: c.extra}
+}
+
 func parseTag(tag string) command {
-	list := make(map[string]string)
+	var (
+		cmd     = command{list: make(map[string]string)}
+		matches = rx.FindAllStringSubmatchIndex(tag, -1)
+	)
 
-	matches := rx.FindAllStringSubmatch(tag, -1)
 	if len(matches) == 0 {
-		list["value"] = tag
-	} else {
-		for _, match := range matches {
-			if len(match) == 3 {
-				list[match[1]] = match[2]
-			}
+		cmd.list["value"] = tag
+		return cmd
+	}
+
+	last := 0
+	for _, match := range matches {
+		cmd.appendExtra(tag[last:match[0]])
+		last = match[1]
+
+		name, arg := tag[match[2]:match[3]], tag[match[4]:match[5]]
+		if cmd.isCMD(name) {
+			cmd.dups = append(cmd.dups, name)
 		}
+		cmd.list[name] = arg
 	}
+	cmd.appendExtra(tag[last:])
+
+	return cmd
+}
 
-	return command{list: list}
+func (c *command) appendExtra(text string) {
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		if _, ok := flagCommands[part]; ok {
+			c.list[part] = ""
+			continue
+		}
+
+		c.extra = append(c.extra, part)
+	}
 }
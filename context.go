@@ -0,0 +1,46 @@
+package autostruct
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+type (
+	clockKey struct{}
+	randKey  struct{}
+)
+
+// ContextWithClock makes time expressions such as now and today read the
+// clock from the context passed to SetContext, overriding WithClock.
+func ContextWithClock(ctx context.Context, clock func() time.Time) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// ContextWithRand makes rand commands draw from r, overriding WithSeed. A
+// *rand.Rand is not safe for concurrent use, so collections are then filled
+// serially even with WithParallel.
+func ContextWithRand(ctx context.Context, r *rand.Rand) context.Context {
+	return context.WithValue(ctx, randKey{}, r)
+}
+
+func contextRand(ctx context.Context) *rand.Rand {
+	r, _ := ctx.Value(randKey{}).(*rand.Rand)
+	return r
+}
+
+func (c *config) now() time.Time {
+	if clock, ok := c.ctx.Value(clockKey{}).(func() time.Time); ok && clock != nil {
+		return clock()
+	}
+
+	return c.clock()
+}
+
+func (c *config) random() *rand.Rand {
+	if r := contextRand(c.ctx); r != nil {
+		return r
+	}
+
+	return c.rand
+}
@@ -0,0 +1,168 @@
+package autostruct
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+var (
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type Change struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+func Diff(v any, opts ...Option) ([]Change, error) {
+	cur := indirect(reflect.ValueOf(v))
+	if !cur.IsValid() {
+		return nil, nil
+	}
+
+	cfg := newConfig(context.Background(), opts...)
+	cfg.unstable = make(map[referenceKey]bool)
+	cfg.parallel = 0
+
+	def := reflect.New(cur.Type())
+	if err := cfg.fill(def); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	walkDiff(def.Elem(), cur, "", reflect.StructField{}, func(path string, field reflect.StructField, a, b reflect.Value) {
+		// defaults such as now or rand differ on every fill, so comparing
+		// against them would always report a change.
+		if cfg.isUnstable(a) {
+			return
+		}
+
+		change := Change{Path: path, Old: cfg.renderSafe(a), New: cfg.renderSafe(b)}
+		if parseTag(fieldTag(cfg, field)).isSecret() {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	})
+
+	return changes, nil
+}
+
+// markUnstable records that v was filled with a value that changes on every
+// fill, together with everything Diff may walk into below it.
+func (c *config) markUnstable(v reflect.Value) {
+	if c.unstable == nil || !v.CanAddr() || c.unstable[keyOf(v)] {
+		return
+	}
+	c.unstable[keyOf(v)] = true
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			c.markUnstable(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.markUnstable(v.Field(i))
+			}
+		}
+	}
+}
+
+func (c *config) isUnstable(v reflect.Value) bool {
+	if c.unstable == nil || !v.CanAddr() {
+		return false
+	}
+
+	visited := make(map[referenceKey]bool)
+
+	var walk func(v reflect.Value) bool
+	walk = func(v reflect.Value) bool {
+		key := keyOf(v)
+		if c.unstable[key] {
+			return true
+		}
+		if visited[key] {
+			return false
+		}
+		visited[key] = true
+
+		switch v.Kind() {
+		case reflect.Pointer:
+			return !v.IsNil() && walk(v.Elem())
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() && walk(v.Field(i)) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return walk(v)
+}
+
+func diffPaths(a, b reflect.Value) []string {
+	var paths []string
+	walkDiff(a, b, "", reflect.StructField{}, func(path string, _ reflect.StructField, _, _ reflect.Value) {
+		paths = append(paths, path)
+	})
+
+	return paths
+}
+
+func walkDiff(a, b reflect.Value, path string, field reflect.StructField, fn func(string, reflect.StructField, reflect.Value, reflect.Value)) {
+	if a.Kind() == reflect.Pointer && b.Kind() == reflect.Pointer && !a.IsNil() && !b.IsNil() &&
+		a.Elem().Kind() == reflect.Struct {
+		walkDiff(a.Elem(), b.Elem(), path, field, fn)
+		return
+	}
+
+	if a.Kind() == reflect.Func || a.Kind() == reflect.Chan {
+		if a.IsNil() != b.IsNil() {
+			fn(path, field, a, b)
+		}
+		return
+	}
+
+	if a.Kind() != reflect.Struct || isOpaque(a.Type()) {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			fn(path, field, a, b)
+		}
+		return
+	}
+
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		walkDiff(a.Field(i), b.Field(i), name, field, fn)
+	}
+}
+
+func isOpaque(typ reflect.Type) bool {
+	if typ.Implements(stringerType) || typ.Implements(textMarshalerType) || typ.Implements(redactedType) {
+		return true
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
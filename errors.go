@@ -0,0 +1,24 @@
+package autostruct
+
+import "fmt"
+
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field [%s]: %s", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func wrapFieldError(name string, err error) error {
+	if fe, ok := err.(*FieldError); ok {
+		return &FieldError{Path: name + "." + fe.Path, Err: fe.Err}
+	}
+
+	return &FieldError{Path: name, Err: err}
+}
@@ -0,0 +1,138 @@
+package autostruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+type Report struct {
+	Fields []FieldReport `json:"fields"`
+}
+
+type FieldReport struct {
+	Path     string            `json:"path"`
+	Type     string            `json:"type"`
+	Tag      string            `json:"tag,omitempty"`
+	Commands map[string]string `json:"commands,omitempty"`
+	Setter   string            `json:"setter,omitempty"`
+	Value    string            `json:"value"`
+	Skipped  bool              `json:"skipped"`
+	Reason   string            `json:"reason,omitempty"`
+
+	value  reflect.Value
+	secret bool
+}
+
+func Explain(v any, opts ...Option) (Report, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return Report{}, fmt.Errorf("[nil] type is not supported. must be struct")
+	}
+
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	typ := rv.Type()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	// fill a deep copy, so explaining never modifies v or what it points to.
+	dry := reflect.New(typ)
+	if rv.Type() == typ {
+		dry.Elem().Set(deepCopy(rv))
+	}
+
+	var report Report
+
+	cfg := newConfig(context.Background(), opts...)
+	cfg.report = &report
+	cfg.parallel = 0
+
+	if err := cfg.fill(dry); err != nil {
+		return Report{}, err
+	}
+
+	for i, f := range report.Fields {
+		if f.secret {
+			report.Fields[i].Value = redacted
+			continue
+		}
+		report.Fields[i].Value = cfg.renderSafe(f.value)
+	}
+
+	return report, nil
+}
+
+func (c *config) explain(field fieldPlan, v reflect.Value, reason string) {
+	if c.report == nil {
+		return
+	}
+
+	fr := FieldReport{
+		Path:    strings.Join(append(c.path[:len(c.path):len(c.path)], field.name), "."),
+		Type:    v.Type().String(),
+		Tag:     field.tag,
+		Setter:  setterName(c, v),
+		Skipped: reason != "",
+		Reason:  reason,
+		value:   v,
+		secret:  field.cmd.isSecret(),
+	}
+
+	if len(field.cmd.list) > 0 {
+		fr.Commands = make(map[string]string, len(field.cmd.list))
+		for name, arg := range field.cmd.list {
+			fr.Commands[name] = arg
+		}
+	}
+
+	c.report.Fields = append(c.report.Fields, fr)
+}
+
+func setterName(cfg *config, v reflect.Value) string {
+	typ := v.Type()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	fn := getSetterFunc(cfg, reflect.New(typ).Elem())
+	if fn == nil {
+		return ""
+	}
+
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimPrefix(name, "auto-struct.")
+
+	// closures such as custom setters are reported by their constructor.
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+func (r Report) String() string {
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tTYPE\tTAG\tSETTER\tVALUE\tSKIPPED")
+
+	for _, f := range r.Fields {
+		skipped := ""
+		if f.Skipped {
+			skipped = f.Reason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Path, f.Type, f.Tag, f.Setter, f.Value, skipped)
+	}
+
+	w.Flush()
+
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package autostruct
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Factory[T any] struct {
+	opts   []Option
+	seqs   *sequences
+	lock   sync.RWMutex
+	traits map[string]func(*T)
+}
+
+func NewFactory[T any](opts ...Option) *Factory[T] {
+	return &Factory[T]{
+		opts:   opts,
+		seqs:   newSequences(),
+		traits: make(map[string]func(*T)),
+	}
+}
+
+func (f *Factory[T]) Trait(name string, fn func(*T)) *Factory[T] {
+	f.lock.Lock()
+	f.traits[name] = fn
+	f.lock.Unlock()
+	return f
+}
+
+func (f *Factory[T]) With(traits ...string) func(*T) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	fns := make([]func(*T), 0, len(traits))
+	for _, name := range traits {
+		fn, ok := f.traits[name]
+		if !ok {
+			panic(fmt.Errorf("unknown trait [%s]", name))
+		}
+		fns = append(fns, fn)
+	}
+
+	return func(v *T) {
+		for _, fn := range fns {
+			fn(v)
+		}
+	}
+}
+
+func (f *Factory[T]) Build(overrides ...func(*T)) T {
+	v := New[T](append(f.opts[:len(f.opts):len(f.opts)], withSequences(f.seqs))...)
+
+	for _, override := range overrides {
+		override(&v)
+	}
+
+	return v
+}
+
+func (f *Factory[T]) BuildList(n int, overrides ...func(*T)) []T {
+	list := make([]T, n)
+	for i := range list {
+		list[i] = f.Build(overrides...)
+	}
+
+	return list
+}
+
+type sequences struct {
+	lock     sync.Mutex
+	counters map[string]int64
+}
+
+func newSequences() *sequences {
+	return &sequences{
+		counters: make(map[string]int64),
+	}
+}
+
+func (s *sequences) next(key string) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.counters[key]++
+	return s.counters[key]
+}
+
+func withSequences(seqs *sequences) Option {
+	return func(c *config) {
+		c.seqs = seqs
+	}
+}
+
+func sequenceValue(cfg *config, arg string) (string, error) {
+	if cfg.seqs == nil {
+		cfg.seqs = newSequences()
+	}
+
+	n := cfg.seqs.next(strings.Join(cfg.path, "."))
+
+	if strings.Contains(arg, "%") {
+		return fmt.Sprintf(arg, n), nil
+	}
+
+	start, err := strconv.ParseInt(arg, 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("sequence requires a start number or a format, got [%s]", arg)
+	}
+
+	return strconv.FormatInt(start+n-1, 10), nil
+}
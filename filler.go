@@ -0,0 +1,36 @@
+package autostruct
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+type Filler struct {
+	cfg   *config
+	calls atomic.Uint64
+}
+
+func NewFiller(opts ...Option) *Filler {
+	cfg := newConfig(context.Background(), opts...)
+	cfg.seqs = newSequences()
+
+	return &Filler{cfg: cfg}
+}
+
+func (f *Filler) Set(v any) error {
+	return f.SetContext(context.Background(), v)
+}
+
+func (f *Filler) SetContext(ctx context.Context, v any) error {
+	cfg := f.cfg.fork(f.calls.Add(1) - 1)
+	cfg.ctx = ctx
+
+	return cfg.fill(reflect.ValueOf(v))
+}
+
+func (f *Filler) MustSet(v any) {
+	if err := f.Set(v); err != nil {
+		panic(err)
+	}
+}
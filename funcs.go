@@ -0,0 +1,63 @@
+package autostruct
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var funcs sync.Map
+
+func RegisterFunc(name string, fn any) {
+	if v := reflect.ValueOf(fn); v.Kind() != reflect.Func || v.IsNil() {
+		panic(fmt.Errorf("RegisterFunc requires a function, got [%T]", fn))
+	}
+
+	funcs.Store(name, reflect.ValueOf(fn))
+}
+
+func funcSetter(cfg *config, v reflect.Value, cmd command) error {
+	if kind := v.Kind(); kind != reflect.Func {
+		return fmt.Errorf("FuncSetter does not support [%s]", kind)
+	}
+
+	name := cmd.value()
+	if cmd.isCMD("func") {
+		name = cmd.cmd("func")
+	}
+
+	// functions may be registered again under the same name, so never
+	// serve them from the cache.
+	cfg.volatile = true
+
+	fn, ok := funcs.Load(name)
+	if !ok {
+		if name != "noop" {
+			return fmt.Errorf("unknown function [%s]", name)
+		}
+		v.Set(noop(v.Type()))
+		return nil
+	}
+
+	fv := fn.(reflect.Value)
+	switch {
+	case fv.Type().AssignableTo(v.Type()):
+		v.Set(fv)
+	case fv.Type().ConvertibleTo(v.Type()):
+		v.Set(fv.Convert(v.Type()))
+	default:
+		return fmt.Errorf("function [%s] of type [%s] is not assignable to [%s]", name, fv.Type(), v.Type())
+	}
+
+	return nil
+}
+
+func noop(typ reflect.Type) reflect.Value {
+	return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, typ.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(typ.Out(i))
+		}
+		return out
+	})
+}
@@ -0,0 +1,47 @@
+package autostruct
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+const redacted = "***"
+
+func (c *config) observe(field fieldPlan, v reflect.Value, reason string) {
+	c.explain(field, v, reason)
+
+	if c.logger == nil || reason == "untagged" {
+		return
+	}
+
+	// references are logged once they are resolved.
+	if reason == "" && field.cmd.isReference() {
+		return
+	}
+
+	c.logValue(strings.Join(append(c.path[:len(c.path):len(c.path)], field.name), "."), v, field.cmd, reason)
+}
+
+func (c *config) logValue(path string, v reflect.Value, cmd command, reason string) {
+	if c.logger == nil || !c.logger.Enabled(c.ctx, slog.LevelDebug) {
+		return
+	}
+
+	value := redacted
+	if !cmd.isSecret() {
+		value = c.renderSafe(v)
+	}
+
+	msg := "autostruct: field set"
+	if reason == "cached" {
+		msg = "autostruct: field set from cache"
+	}
+
+	c.logger.LogAttrs(c.ctx, slog.LevelDebug, msg,
+		slog.String("path", path),
+		slog.String("type", v.Type().String()),
+		slog.String("source", cmd.source()),
+		slog.String("value", value),
+	)
+}
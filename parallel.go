@@ -0,0 +1,127 @@
+package autostruct
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"sync"
+)
+
+const parallelThreshold = 128
+
+func (c *config) fork(i uint64) *config {
+	elem := *c
+	elem.scopes = append([]reflect.Value(nil), c.scopes...)
+	elem.path = append([]string(nil), c.path...)
+	elem.volatile = false
+
+	if c.seeded {
+		// every fork gets its own source derived from its index, so the
+		// result does not depend on the order in which forks are filled.
+		elem.seed = c.seed ^ (i+1)*0x9e3779b97f4a7c15
+		elem.rand = rand.New(rand.NewPCG(elem.seed, 0))
+	}
+
+	return &elem
+}
+
+func fillEach(cfg *config, s reflect.Value, tag string) error {
+	if cfg.seqs == nil {
+		cfg.seqs = newSequences()
+	}
+
+	var (
+		n       = s.Len()
+		workers = min(cfg.parallel, n)
+		errs    = make([]error, n)
+		elems   = make([]*config, n)
+	)
+
+	fill := func(i int) {
+		if err := cfg.ctx.Err(); err != nil {
+			errs[i] = err
+			return
+		}
+
+		elem := cfg.fork(uint64(i))
+		elems[i] = elem
+		errs[i] = withReferenceScope(elem, func() error {
+			return valueSetterRaw(elem, s.Index(i), tag)
+		})
+	}
+
+	if n < parallelThreshold || usesSequences(cfg, s.Type().Elem(), tag) || contextRand(cfg.ctx) != nil {
+		for i := 0; i < n; i++ {
+			if fill(i); errs[i] != nil {
+				return errs[i]
+			}
+		}
+	} else {
+		var (
+			wg   sync.WaitGroup
+			jobs = make(chan int)
+		)
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					fill(i)
+				}
+			}()
+		}
+
+		for i := 0; i < n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		cfg.volatile = cfg.volatile || elems[i].volatile
+	}
+
+	return nil
+}
+
+// usesSequences reports whether filling typ with tag consumes seq counters,
+// whose values depend on fill order and therefore require a serial fill.
+func usesSequences(cfg *config, typ reflect.Type, tag string) bool {
+	if parseTag(tag).isCMD("seq") {
+		return true
+	}
+
+	visited := make(map[reflect.Type]bool)
+
+	var walk func(typ reflect.Type) bool
+	walk = func(typ reflect.Type) bool {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Chan:
+			return walk(typ.Elem())
+		case reflect.Map:
+			return walk(typ.Key()) || walk(typ.Elem())
+		}
+
+		if typ.Kind() != reflect.Struct || visited[typ] {
+			return false
+		}
+		visited[typ] = true
+
+		for _, field := range getPlan(cfg, typ).fields {
+			if field.cmd.isCMD("seq") || (field.cmd.isRepeat() && parseTag(field.cmd.repeat()).isCMD("seq")) {
+				return true
+			}
+			if walk(typ.Field(field.index).Type) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return walk(typ)
+}
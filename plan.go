@@ -0,0 +1,89 @@
+package autostruct
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPlans bounds the compiled plans kept across calls, which grow with every
+// distinct type, tag and profile, e.g. when profiles are chosen per tenant.
+const maxPlans = 4096
+
+var (
+	plans     sync.Map
+	planCount atomic.Int64
+)
+
+func ClearPlans() {
+	plans.Clear()
+	planCount.Store(0)
+}
+
+type planKey struct {
+	typ     reflect.Type
+	tag     string
+	profile string
+}
+
+type plan struct {
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	index int
+	name  string
+	key   CacheKey
+	tag   string
+	cmd   command
+}
+
+func getPlan(cfg *config, typ reflect.Type) *plan {
+	key := planKey{typ: typ, tag: cfg.tag, profile: cfg.profile}
+
+	if p, ok := plans.Load(key); ok {
+		return p.(*plan)
+	}
+
+	p, loaded := plans.LoadOrStore(key, compilePlan(cfg, typ))
+	if !loaded && planCount.Add(1) > maxPlans {
+		// plans are cheap to compile again, so start over instead of
+		// tracking which ones are still in use.
+		ClearPlans()
+	}
+
+	return p.(*plan)
+}
+
+func compilePlan(cfg *config, typ reflect.Type) *plan {
+	p := &plan{fields: make([]fieldPlan, 0, typ.NumField())}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		fp := fieldPlan{
+			index: i,
+			name:  field.Name,
+			key:   CacheKey{Type: typ, Field: field.Name, Tag: cfg.tag, Profile: cfg.profile},
+			tag:   fieldTag(cfg, field),
+		}
+
+		if fp.tag != "" {
+			fp.cmd = parseTag(fp.tag)
+		}
+
+		p.fields = append(p.fields, fp)
+	}
+
+	return p
+}
+
+func fieldTag(cfg *config, field reflect.StructField) string {
+	if cfg.profile != "" {
+		if tag, ok := field.Tag.Lookup(cfg.tag + "." + cfg.profile); ok {
+			return tag
+		}
+	}
+
+	return field.Tag.Get(cfg.tag)
+}
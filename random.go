@@ -0,0 +1,138 @@
+package autostruct
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c *config) int64N(n int64) int64 {
+	if r := c.random(); r != nil {
+		return r.Int64N(n)
+	}
+	return rand.Int64N(n)
+}
+
+func (c *config) uint64N(n uint64) uint64 {
+	if r := c.random(); r != nil {
+		return r.Uint64N(n)
+	}
+	return rand.Uint64N(n)
+}
+
+func (c *config) uint64() uint64 {
+	if r := c.random(); r != nil {
+		return r.Uint64()
+	}
+	return rand.Uint64()
+}
+
+func (c *config) int64Range(lo, hi int64) int64 {
+	if n := hi - lo + 1; n > 0 {
+		return lo + c.int64N(n)
+	}
+
+	// the span does not fit an int64, so draw it as unsigned and wrap.
+	return lo + int64(c.uint64Range(0, uint64(hi)-uint64(lo)))
+}
+
+func (c *config) uint64Range(lo, hi uint64) uint64 {
+	if n := hi - lo + 1; n > 0 {
+		return lo + c.uint64N(n)
+	}
+
+	return c.uint64()
+}
+
+func (c *config) float64() float64 {
+	if r := c.random(); r != nil {
+		return r.Float64()
+	}
+	return rand.Float64()
+}
+
+func randomValue(cfg *config, typ reflect.Type, arg string) (string, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	minStr, maxStr, ok := strings.Cut(arg, ",")
+	if !ok {
+		return "", fmt.Errorf("rand requires min and max, got [%s]", arg)
+	}
+	minStr, maxStr = strings.TrimSpace(minStr), strings.TrimSpace(maxStr)
+
+	if typ == durationType {
+		lo, err := time.ParseDuration(minStr)
+		if err != nil {
+			return "", err
+		}
+
+		hi, err := time.ParseDuration(maxStr)
+		if err != nil {
+			return "", err
+		}
+
+		if hi < lo {
+			return "", fmt.Errorf("rand max [%s] is less than min [%s]", hi, lo)
+		}
+
+		return time.Duration(cfg.int64Range(int64(lo), int64(hi))).String(), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lo, err := strconv.ParseInt(minStr, 0, 64)
+		if err != nil {
+			return "", err
+		}
+
+		hi, err := strconv.ParseInt(maxStr, 0, 64)
+		if err != nil {
+			return "", err
+		}
+
+		if hi < lo {
+			return "", fmt.Errorf("rand max [%d] is less than min [%d]", hi, lo)
+		}
+
+		return strconv.FormatInt(cfg.int64Range(lo, hi), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lo, err := strconv.ParseUint(minStr, 0, 64)
+		if err != nil {
+			return "", err
+		}
+
+		hi, err := strconv.ParseUint(maxStr, 0, 64)
+		if err != nil {
+			return "", err
+		}
+
+		if hi < lo {
+			return "", fmt.Errorf("rand max [%d] is less than min [%d]", hi, lo)
+		}
+
+		return strconv.FormatUint(cfg.uint64Range(lo, hi), 10), nil
+	case reflect.Float32, reflect.Float64:
+		lo, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			return "", err
+		}
+
+		hi, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil {
+			return "", err
+		}
+
+		if hi < lo {
+			return "", fmt.Errorf("rand max [%g] is less than min [%g]", hi, lo)
+		}
+
+		return strconv.FormatFloat(lo+cfg.float64()*(hi-lo), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("rand does not support [%s]", typ)
+	}
+}
@@ -0,0 +1,142 @@
+package autostruct
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+)
+
+var redactedType = reflect.TypeOf((*redactor)(nil)).Elem()
+
+type redactor interface {
+	redacted()
+}
+
+type Redacted[T any] struct {
+	Value T
+}
+
+func (r Redacted[T]) redacted() {}
+
+func (r Redacted[T]) Get() T {
+	return r.Value
+}
+
+func (r Redacted[T]) String() string {
+	return redacted
+}
+
+func (r Redacted[T]) GoString() string {
+	return redacted
+}
+
+func (r Redacted[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (r Redacted[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (r *Redacted[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Value)
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// renderSafe renders v like render, but masks fields tagged as secret at any
+// depth, so composites holding secrets don't leak them.
+func (c *config) renderSafe(v reflect.Value) string {
+	if v.IsValid() && hasSecrets(c, v.Type(), map[reflect.Type]bool{}) {
+		v = maskSecrets(c, v)
+	}
+
+	return render(v)
+}
+
+func isSecretField(c *config, field reflect.StructField) bool {
+	return parseTag(fieldTag(c, field)).isSecret()
+}
+
+func hasSecrets(c *config, typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return hasSecrets(c, typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if isSecretField(c, field) || hasSecrets(c, field.Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func maskSecrets(c *config, v reflect.Value) reflect.Value {
+	if !hasSecrets(c, v.Type(), map[reflect.Type]bool{}) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		return maskSecrets(c, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = maskSecrets(c, v.Index(i)).Interface()
+		}
+		return reflect.ValueOf(out)
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), anyType), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), maskSecrets(c, iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		var (
+			fields []reflect.StructField
+			values []reflect.Value
+		)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			val := reflect.ValueOf(redacted)
+			if !isSecretField(c, field) {
+				val = maskSecrets(c, v.Field(i))
+			}
+
+			fields = append(fields, reflect.StructField{Name: field.Name, Type: anyType, Tag: field.Tag})
+			values = append(values, val)
+		}
+
+		out := reflect.New(reflect.StructOf(fields)).Elem()
+		for i, val := range values {
+			out.Field(i).Set(val)
+		}
+		return out
+	}
+
+	return v
+}
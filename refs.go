@@ -0,0 +1,500 @@
+package autostruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type reference struct {
+	path   string
+	abs    string
+	value  reflect.Value
+	cmd    command
+	scopes []reflect.Value
+}
+
+type referenceScope struct {
+	base int
+	refs []*reference
+}
+
+type referenceKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func keyOf(v reflect.Value) referenceKey {
+	return referenceKey{addr: v.UnsafeAddr(), typ: v.Type()}
+}
+
+func withReferenceScope(cfg *config, fn func() error) error {
+	outer := cfg.refs
+	cfg.refs = &referenceScope{base: len(cfg.path)}
+	defer func() { cfg.refs = outer }()
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	return resolveReferences(cfg, cfg.refs)
+}
+
+func deferReference(cfg *config, v reflect.Value, cmd command) {
+	// the value depends on other fields, so it is never cached.
+	cfg.volatile = true
+
+	cfg.refs.refs = append(cfg.refs.refs, &reference{
+		path:   strings.Join(cfg.path[cfg.refs.base:], "."),
+		abs:    strings.Join(cfg.path, "."),
+		value:  v,
+		cmd:    cmd,
+		scopes: append([]reflect.Value(nil), cfg.scopes...),
+	})
+}
+
+func resolveReferences(cfg *config, scope *referenceScope) error {
+	if len(scope.refs) == 0 {
+		return nil
+	}
+
+	var (
+		index = make(map[referenceKey]*reference, len(scope.refs))
+		state = make(map[*reference]int, len(scope.refs))
+		chain []string
+	)
+
+	for _, ref := range scope.refs {
+		index[keyOf(ref.value)] = ref
+	}
+
+	var visit func(ref *reference) error
+	visit = func(ref *reference) error {
+		switch state[ref] {
+		case 1:
+			return &FieldError{
+				Path: ref.path,
+				Err:  fmt.Errorf("reference cycle [%s -> %s]", strings.Join(chain, " -> "), ref.path),
+			}
+		case 2:
+			return nil
+		}
+
+		state[ref] = 1
+		chain = append(chain, ref.path)
+
+		deps, err := ref.dependencies()
+		if err != nil {
+			return &FieldError{Path: ref.path, Err: err}
+		}
+
+		for _, dep := range deps {
+			if next, ok := index[keyOf(dep)]; ok {
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := ref.resolve(cfg); err != nil {
+			return &FieldError{Path: ref.path, Err: err}
+		}
+
+		for _, dep := range deps {
+			if cfg.isUnstable(dep) {
+				cfg.markUnstable(ref.value)
+				break
+			}
+		}
+
+		cfg.logValue(ref.abs, ref.value, ref.cmd, "")
+
+		chain = chain[:len(chain)-1]
+		state[ref] = 2
+
+		return nil
+	}
+
+	for _, ref := range scope.refs {
+		if err := visit(ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *reference) dependencies() ([]reflect.Value, error) {
+	var paths []string
+
+	switch {
+	case r.cmd.isCMD("ref"):
+		paths = []string{strings.TrimSpace(r.cmd.cmd("ref"))}
+	case r.cmd.isCMD("expr"):
+		expr, err := parser.ParseExpr(r.cmd.cmd("expr"))
+		if err != nil {
+			return nil, err
+		}
+		paths = exprPaths(expr)
+	case r.cmd.isCMD("fmt"):
+		_, args, err := splitFormat(r.cmd.cmd("fmt"))
+		if err != nil {
+			return nil, err
+		}
+		paths = args
+	case r.cmd.isCMD("template"):
+		tmpl, err := parseTemplate(r.cmd.cmd("template"))
+		if err != nil {
+			return nil, err
+		}
+		paths = templatePaths(tmpl.Tree.Root)
+	}
+
+	deps := make([]reflect.Value, 0, len(paths))
+	for _, path := range paths {
+		dep, err := r.lookup(path)
+		if err != nil {
+			// a template reports its own missing keys when it is executed.
+			if r.cmd.isCMD("template") {
+				continue
+			}
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+func (r *reference) resolve(cfg *config) error {
+	switch {
+	case r.cmd.isCMD("ref"):
+		src, err := r.lookup(strings.TrimSpace(r.cmd.cmd("ref")))
+		if err != nil {
+			return err
+		}
+
+		if src.Type().AssignableTo(r.value.Type()) {
+			r.value.Set(src)
+			return nil
+		}
+
+		return r.set(cfg, render(src))
+	case r.cmd.isCMD("expr"):
+		expr, err := parser.ParseExpr(r.cmd.cmd("expr"))
+		if err != nil {
+			return err
+		}
+
+		c, err := r.eval(expr)
+		if err != nil {
+			return err
+		}
+
+		s, err := renderConstant(c, r.value.Type())
+		if err != nil {
+			return err
+		}
+
+		return r.set(cfg, s)
+	case r.cmd.isCMD("fmt"):
+		format, paths, err := splitFormat(r.cmd.cmd("fmt"))
+		if err != nil {
+			return err
+		}
+
+		args := make([]any, 0, len(paths))
+		for _, path := range paths {
+			arg, err := r.lookup(path)
+			if err != nil {
+				return err
+			}
+			args = append(args, indirect(arg).Interface())
+		}
+
+		return r.set(cfg, fmt.Sprintf(format, args...))
+	case r.cmd.isCMD("template"):
+		s, err := executeTemplate(r.cmd.cmd("template"), r.scopes[len(r.scopes)-1])
+		if err != nil {
+			return fmt.Errorf("command [template]: %w", err)
+		}
+
+		return r.set(cfg, s)
+	}
+
+	return nil
+}
+
+func (r *reference) set(cfg *config, value string) error {
+	list := make(map[string]string, len(r.cmd.list))
+	for k, v := range r.cmd.list {
+		if k != "ref" && k != "expr" && k != "fmt" && k != "template" {
+			list[k] = v
+		}
+	}
+
+	if isBytes(r.value.Type()) {
+		list["byte"] = value
+	} else {
+		list["value"] = value
+	}
+
+	return valueSetterCmd(cfg, r.value, command{list: list})
+}
+
+func (r *reference) lookup(path string) (reflect.Value, error) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if v, ok := lookupField(r.scopes[i], path); ok {
+			return v, nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown field reference [%s]", path)
+}
+
+func lookupField(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		v = indirect(v)
+		if !v.IsValid() || v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		field, ok := v.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return reflect.Value{}, false
+		}
+
+		v = v.FieldByIndex(field.Index)
+	}
+
+	return v, true
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func (r *reference) eval(expr ast.Expr) (constant.Value, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0), nil
+	case *ast.ParenExpr:
+		return r.eval(e.X)
+	case *ast.Ident, *ast.SelectorExpr:
+		path := selectorPath(e)
+		if path == "true" || path == "false" {
+			return constant.MakeBool(path == "true"), nil
+		}
+
+		v, err := r.lookup(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return toConstant(v)
+	case *ast.UnaryExpr:
+		x, err := r.eval(e.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if x.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("invalid operand for [%s]", e.Op)
+		}
+
+		return constant.UnaryOp(e.Op, x, 0), nil
+	case *ast.BinaryExpr:
+		x, err := r.eval(e.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := r.eval(e.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return binaryOp(x, e.Op, y)
+	default:
+		return nil, fmt.Errorf("unsupported expression [%T]", expr)
+	}
+}
+
+func binaryOp(x constant.Value, op token.Token, y constant.Value) (c constant.Value, err error) {
+	defer func() {
+		// go/constant panics on mismatched operands, e.g. "a" * 2.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid operation [%s %s %s]", x, op, y)
+		}
+	}()
+
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return constant.MakeBool(constant.Compare(x, op, y)), nil
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(y)
+		if !ok {
+			return nil, fmt.Errorf("invalid shift count [%s]", y)
+		}
+		return constant.Shift(x, op, uint(s)), nil
+	case token.QUO:
+		if y.Kind() != constant.Bool && constant.Sign(y) == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			op = token.QUO_ASSIGN
+		}
+	case token.REM:
+		if constant.Sign(y) == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+	}
+
+	c = constant.BinaryOp(x, op, y)
+	if c.Kind() == constant.Unknown {
+		return nil, fmt.Errorf("invalid operation [%s %s %s]", x, op, y)
+	}
+
+	return c, nil
+}
+
+func toConstant(v reflect.Value) (constant.Value, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("nil value in expression")
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return constant.MakeBool(v.Bool()), nil
+	case reflect.String:
+		return constant.MakeString(v.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return constant.MakeInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return constant.MakeUint64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return constant.MakeFloat64(v.Float()), nil
+	default:
+		return nil, fmt.Errorf("type [%s] is not supported in expressions", v.Type())
+	}
+}
+
+func renderConstant(c constant.Value, typ reflect.Type) (string, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == durationType {
+		i, ok := constant.Int64Val(constant.ToInt(c))
+		if !ok {
+			return "", fmt.Errorf("expression result [%s] is not a duration", c)
+		}
+		return time.Duration(i).String(), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i := constant.ToInt(c)
+		if i.Kind() != constant.Int {
+			return "", fmt.Errorf("expression result [%s] is not an integer", c)
+		}
+		return i.ExactString(), nil
+	case reflect.Float32, reflect.Float64:
+		f, _ := constant.Float64Val(constant.ToFloat(c))
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case reflect.String:
+		if c.Kind() == constant.String {
+			return constant.StringVal(c), nil
+		}
+	}
+
+	return c.ExactString(), nil
+}
+
+func render(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if b, err := json.Marshal(v.Interface()); err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func selectorPath(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		if prefix := selectorPath(e.X); prefix != "" {
+			return prefix + "." + e.Sel.Name
+		}
+	}
+
+	return ""
+}
+
+func exprPaths(expr ast.Expr) []string {
+	var paths []string
+
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.SelectorExpr:
+			if path := selectorPath(e); path != "" {
+				paths = append(paths, path)
+			}
+			return false
+		case *ast.Ident:
+			if e.Name != "true" && e.Name != "false" {
+				paths = append(paths, e.Name)
+			}
+		}
+		return true
+	})
+
+	return paths
+}
+
+func splitFormat(arg string) (string, []string, error) {
+	var (
+		parts = strings.Split(arg, ",")
+		verbs = strings.Count(strings.ReplaceAll(arg, "%%", ""), "%")
+	)
+
+	if verbs >= len(parts) {
+		return "", nil, fmt.Errorf("format [%s] has %d verbs but only %d arguments", arg, verbs, len(parts)-1)
+	}
+
+	paths := make([]string, 0, verbs)
+	for _, part := range parts[len(parts)-verbs:] {
+		paths = append(paths, strings.TrimSpace(part))
+	}
+
+	return strings.Join(parts[:len(parts)-verbs], ","), paths, nil
+}
@@ -1,8 +1,10 @@
 package autostruct
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strconv"
 	"strings"
@@ -15,6 +17,7 @@ var (
 	timeType       = reflect.TypeOf(time.Time{})
 	durationType   = reflect.TypeOf(time.Duration(0))
 	jsonRawMessage = reflect.TypeOf(json.RawMessage{})
+	scannerType    = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
 	timeFormats    = map[string]string{
 		"ANSIC":       time.ANSIC,
 		"UnixDate":    time.UnixDate,
@@ -35,9 +38,40 @@ var (
 		"DateOnly":    time.DateOnly,
 		"TimeOnly":    time.TimeOnly,
 	}
+	timeExprs = map[string]func(now time.Time) time.Time{
+		"now": func(now time.Time) time.Time {
+			return now
+		},
+		"today": func(now time.Time) time.Time {
+			return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		},
+		"startOfMonth": func(now time.Time) time.Time {
+			return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		},
+	}
 )
 
-func getSetterFunc(v reflect.Value) setterFunc {
+func getSetterFunc(cfg *config, v reflect.Value) setterFunc {
+	if fn, ok := cfg.setters[v.Type()]; ok {
+		return customSetter(fn)
+	}
+
+	if v.Type().Implements(redactedType) {
+		return redactedSetter
+	}
+
+	if isNullType(v.Type()) {
+		return nullSetter
+	}
+
+	if isAtomicType(v.Type()) {
+		return atomicSetter
+	}
+
+	if fn, ok := stdSetters[v.Type()]; ok {
+		return fn
+	}
+
 	switch v.Type() {
 	case durationType:
 		return durationSetter
@@ -74,6 +108,8 @@ func getSetterFunc(v reflect.Value) setterFunc {
 		return uint32Setter
 	case reflect.Uint64:
 		return uint64Setter
+	case reflect.Uintptr:
+		return uint0Setter
 	case reflect.Float32:
 		return float32Setter
 	case reflect.Float64:
@@ -94,11 +130,83 @@ func getSetterFunc(v reflect.Value) setterFunc {
 		return chanSetter
 	case reflect.Interface:
 		return interfaceSetter
+	case reflect.Func:
+		return funcSetter
 	default:
 		return nil
 	}
 }
 
+func customSetter(fn SetterFunc) setterFunc {
+	return func(cfg *config, v reflect.Value, cmd command) error {
+		// custom setters may depend on request-scoped data from the context,
+		// so their results must never be served from the cache.
+		cfg.volatile = true
+		if err := fn(cfg.ctx, v, cmd.value()); err != nil {
+			return err
+		}
+
+		cfg.markUnstable(v)
+		return nil
+	}
+}
+
+func redactedSetter(cfg *config, v reflect.Value, cmd command) error {
+	if !v.Type().Implements(redactedType) {
+		return fmt.Errorf("RedactedSetter does not support [%s]", v.Kind())
+	}
+
+	return valueSetterCmd(cfg, v.Field(0), cmd)
+}
+
+func nullSetter(cfg *config, v reflect.Value, cmd command) error {
+	if !isNullType(v.Type()) {
+		return fmt.Errorf("NullSetter does not support [%s]", v.Type())
+	}
+
+	if err := valueSetterCmd(cfg, v.Field(0), cmd); err != nil {
+		return err
+	}
+
+	v.Field(1).SetBool(true)
+
+	return nil
+}
+
+func isNullType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.NumField() == 2 &&
+		typ.Field(1).Name == "Valid" && typ.Field(1).Type.Kind() == reflect.Bool &&
+		reflect.PointerTo(typ).Implements(scannerType)
+}
+
+func atomicSetter(cfg *config, v reflect.Value, cmd command) error {
+	if !isAtomicType(v.Type()) || !v.CanAddr() {
+		return fmt.Errorf("AtomicSetter does not support [%s]", v.Type())
+	}
+
+	store := v.Addr().MethodByName("Store")
+
+	arg := reflect.New(store.Type().In(0)).Elem()
+	if arg.Kind() == reflect.Interface {
+		arg.Set(reflect.ValueOf(cmd.value()))
+	} else if err := valueSetterCmd(cfg, arg, cmd); err != nil {
+		return err
+	}
+
+	store.Call([]reflect.Value{arg})
+
+	return nil
+}
+
+func isAtomicType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.PkgPath() != "sync/atomic" {
+		return false
+	}
+
+	m, ok := reflect.PointerTo(typ).MethodByName("Store")
+	return ok && m.Type.NumIn() == 2
+}
+
 func boolSetter(cfg *config, v reflect.Value, cmd command) error {
 	if kind := v.Kind(); kind != reflect.Bool {
 		return fmt.Errorf("BoolSetter does not support [%s]", kind)
@@ -176,12 +284,21 @@ func int64Setter(cfg *config, v reflect.Value, cmd command) error {
 	return intSetter(cfg, v, cmd, 64)
 }
 
-func intSetter(_ *config, v reflect.Value, cmd command, bitSize int) error {
+func intSetter(cfg *config, v reflect.Value, cmd command, bitSize int) error {
 	if !v.CanInt() {
 		return fmt.Errorf("Int%dSetter does not support [%s]", bitSize, v.Kind())
 	}
 
-	i, err := strconv.ParseInt(cmd.value(), 10, bitSize)
+	if _, _, ok := cmd.unit(); ok {
+		return unitSetter(cfg, v, cmd)
+	}
+
+	base, str, err := numberBase(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	i, err := strconv.ParseInt(str, base, bitSize)
 	if err != nil {
 		return err
 	}
@@ -191,6 +308,25 @@ func intSetter(_ *config, v reflect.Value, cmd command, bitSize int) error {
 	return nil
 }
 
+// numberBase keeps plain literals decimal, so leading zeros don't turn them
+// into octal. Underscores follow Go's rules and may only separate digits.
+func numberBase(s string) (int, string, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("xXoObB", rune(digits[1])) {
+		return 0, s, nil
+	}
+
+	if !strings.Contains(digits, "_") {
+		return 10, s, nil
+	}
+
+	if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") || strings.Contains(digits, "__") {
+		return 0, "", fmt.Errorf("invalid underscore placement in [%s]", s)
+	}
+
+	return 10, strings.ReplaceAll(s, "_", ""), nil
+}
+
 func uint0Setter(cfg *config, v reflect.Value, cmd command) error {
 	return uintSetter(cfg, v, cmd, 0)
 }
@@ -243,12 +379,21 @@ func uint64Setter(cfg *config, v reflect.Value, cmd command) error {
 	return uintSetter(cfg, v, cmd, 64)
 }
 
-func uintSetter(_ *config, v reflect.Value, cmd command, bitSize int) error {
+func uintSetter(cfg *config, v reflect.Value, cmd command, bitSize int) error {
 	if !v.CanUint() {
 		return fmt.Errorf("Uint%dSetter does not support [%s]", bitSize, v.Kind())
 	}
 
-	i, err := strconv.ParseUint(cmd.value(), 10, bitSize)
+	if _, _, ok := cmd.unit(); ok {
+		return unitSetter(cfg, v, cmd)
+	}
+
+	base, str, err := numberBase(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	i, err := strconv.ParseUint(str, base, bitSize)
 	if err != nil {
 		return err
 	}
@@ -266,11 +411,15 @@ func float64Setter(cfg *config, v reflect.Value, cmd command) error {
 	return floatSetter(cfg, v, cmd, 64)
 }
 
-func floatSetter(_ *config, v reflect.Value, cmd command, bitSize int) error {
+func floatSetter(cfg *config, v reflect.Value, cmd command, bitSize int) error {
 	if !v.CanFloat() {
 		return fmt.Errorf("Float%dSetter does not support [%s]", bitSize, v.Kind())
 	}
 
+	if _, _, ok := cmd.unit(); ok {
+		return unitSetter(cfg, v, cmd)
+	}
+
 	f, err := strconv.ParseFloat(cmd.value(), bitSize)
 	if err != nil {
 		return err
@@ -281,6 +430,42 @@ func floatSetter(_ *config, v reflect.Value, cmd command, bitSize int) error {
 	return nil
 }
 
+func unitSetter(_ *config, v reflect.Value, cmd command) error {
+	name, arg, _ := cmd.unit()
+
+	r, err := parseUnit(name, arg)
+	if err != nil {
+		return err
+	}
+
+	if (v.CanInt() || v.CanUint()) && !r.IsInt() {
+		return fmt.Errorf("value [%s] is not a whole number for [%s]", arg, v.Type())
+	}
+
+	switch {
+	case v.CanInt():
+		if !r.Num().IsInt64() || v.OverflowInt(r.Num().Int64()) {
+			return fmt.Errorf("value [%s] overflows [%s]", arg, v.Type())
+		}
+		v.SetInt(r.Num().Int64())
+	case v.CanUint():
+		if r.Sign() < 0 || !r.Num().IsUint64() || v.OverflowUint(r.Num().Uint64()) {
+			return fmt.Errorf("value [%s] overflows [%s]", arg, v.Type())
+		}
+		v.SetUint(r.Num().Uint64())
+	case v.CanFloat():
+		f, _ := r.Float64()
+		if v.OverflowFloat(f) {
+			return fmt.Errorf("value [%s] overflows [%s]", arg, v.Type())
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("UnitSetter does not support [%s]", v.Kind())
+	}
+
+	return nil
+}
+
 func complex64Setter(cfg *config, v reflect.Value, cmd command) error {
 	return complexSetter(cfg, v, cmd, 64)
 }
@@ -337,15 +522,24 @@ func arraySetter(cfg *config, v reflect.Value, cmd command) error {
 		return json.Unmarshal([]byte(cmd.value()), v.Addr().Interface())
 	}
 
+	if cfg.parallel > 0 && cmd.isRepeat() {
+		return fillEach(cfg, v, cmd.value())
+	}
+
 	rv := reflect.New(v.Type().Elem()).Elem()
 
 	if cmd.isRepeat() {
-		if err := valueSetterRaw(cfg, rv, cmd.value()); err != nil {
+		if err := withReferenceScope(cfg, func() error {
+			return valueSetterRaw(cfg, rv, cmd.value())
+		}); err != nil {
 			return err
 		}
 	}
 
 	for i := 0; i < v.Len(); i++ {
+		if err := cfg.ctx.Err(); err != nil {
+			return err
+		}
 		v.Index(i).Set(rv)
 	}
 
@@ -380,16 +574,25 @@ func sliceSetter(cfg *config, v reflect.Value, cmd command) error {
 
 	s := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), len, cap)
 
-	if cmd.len() > 0 {
+	if cfg.parallel > 0 && cmd.isRepeat() {
+		if err := fillEach(cfg, s, cmd.value()); err != nil {
+			return err
+		}
+	} else if cmd.len() > 0 {
 		rv := reflect.New(v.Type().Elem()).Elem()
 
 		if cmd.isRepeat() {
-			if err := valueSetterRaw(cfg, rv, cmd.value()); err != nil {
+			if err := withReferenceScope(cfg, func() error {
+				return valueSetterRaw(cfg, rv, cmd.value())
+			}); err != nil {
 				return err
 			}
 		}
 
 		for i := 0; i < s.Len(); i++ {
+			if err := cfg.ctx.Err(); err != nil {
+				return err
+			}
 			s.Index(i).Set(rv)
 		}
 	}
@@ -427,12 +630,14 @@ func mapSetter(cfg *config, v reflect.Value, cmd command) error {
 		)
 
 		key := reflect.New(keyType).Elem()
-		if err := valueSetterRaw(cfg, key, keyStr); err != nil {
-			return err
-		}
-
 		val := reflect.New(valType).Elem()
-		if err := valueSetterRaw(cfg, val, valStr); err != nil {
+
+		if err := withReferenceScope(cfg, func() error {
+			if err := valueSetterRaw(cfg, key, keyStr); err != nil {
+				return err
+			}
+			return valueSetterRaw(cfg, val, valStr)
+		}); err != nil {
 			return err
 		}
 
@@ -449,13 +654,71 @@ func chanSetter(cfg *config, v reflect.Value, cmd command) error {
 		return fmt.Errorf("ChanSetter does not support [%s]", kind)
 	}
 
-	if cmd.isChannel() {
-		v.Set(reflect.MakeChan(reflect.ChanOf(reflect.BothDir, v.Type().Elem()), cmd.buffer()))
+	if !cmd.isChannel() && cmd.value() != "chan" {
+		return nil
+	}
+
+	// channels have identity, so a cached channel would be shared by every
+	// value filled from the cache.
+	cfg.volatile = true
+
+	ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, v.Type().Elem()), cmd.buffer())
+
+	items, err := chanItems(cfg, v.Type().Elem(), cmd)
+	if err != nil {
+		return err
 	}
 
+	if len(items) > ch.Cap() {
+		return fmt.Errorf("prefill count [%d] exceeds channel buffer [%d]", len(items), ch.Cap())
+	}
+
+	for _, item := range items {
+		ch.Send(item)
+	}
+
+	v.Set(ch.Convert(v.Type()))
+
 	return nil
 }
 
+func chanItems(cfg *config, typ reflect.Type, cmd command) ([]reflect.Value, error) {
+	switch {
+	case cmd.isCMD("items"):
+		var items []reflect.Value
+		for _, raw := range strings.Split(cmd.cmd("items"), ",") {
+			item := reflect.New(typ).Elem()
+			if err := withReferenceScope(cfg, func() error {
+				return valueSetterRaw(cfg, item, strings.TrimSpace(raw))
+			}); err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cmd.isRepeat():
+		n := cmd.buffer()
+		if cmd.isCMD("len") {
+			n = cmd.len()
+		}
+
+		item := reflect.New(typ).Elem()
+		if err := withReferenceScope(cfg, func() error {
+			return valueSetterRaw(cfg, item, cmd.repeat())
+		}); err != nil {
+			return nil, err
+		}
+
+		items := make([]reflect.Value, n)
+		for i := range items {
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	return nil, nil
+}
+
 func interfaceSetter(_ *config, v reflect.Value, cmd command) error {
 	if kind := v.Kind(); kind != reflect.Interface {
 		return fmt.Errorf("InterfaceSetter does not support [%s]", kind)
@@ -483,14 +746,57 @@ func durationSetter(_ *config, v reflect.Value, cmd command) error {
 	return nil
 }
 
-func timeSetter(_ *config, v reflect.Value, cmd command) error {
+func timeSetter(cfg *config, v reflect.Value, cmd command) error {
 	if v.Type() != timeType {
 		return fmt.Errorf("TimeSetter does not support [%s]", v.Kind())
 	}
 
-	t, err := time.Parse(parseTimeLayout(cmd.layout()), cmd.value())
-	if err != nil {
-		return err
+	loc := time.UTC
+	if name := cmd.tz(); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+
+	var t time.Time
+
+	switch {
+	case cmd.isUnix():
+		sec, err := strconv.ParseInt(cmd.unix(), 10, 64)
+		if err != nil {
+			return err
+		}
+		t = time.Unix(sec, 0).In(loc)
+	case cmd.isUnixMilli():
+		msec, err := strconv.ParseInt(cmd.unixMilli(), 10, 64)
+		if err != nil {
+			return err
+		}
+		t = time.UnixMilli(msec).In(loc)
+	default:
+		expr, ok, err := parseTimeExpr(cmd.value())
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			now := cfg.now()
+			if cmd.tz() != "" {
+				now = now.In(loc)
+			}
+			t = expr(now)
+			// relative expressions depend on the clock and must not be cached.
+			cfg.volatile = true
+			cfg.markUnstable(v)
+			break
+		}
+
+		t, err = time.ParseInLocation(parseTimeLayout(cmd.layout()), cmd.value(), loc)
+		if err != nil {
+			return err
+		}
 	}
 
 	v.Set(reflect.ValueOf(t))
@@ -508,6 +814,30 @@ func jsonRawMessageSetter(_ *config, v reflect.Value, cmd command) error {
 	return nil
 }
 
+func parseTimeExpr(value string) (func(time.Time) time.Time, bool, error) {
+	for name, base := range timeExprs {
+		rest, found := strings.CutPrefix(value, name)
+		if !found || (rest != "" && rest[0] != '+' && rest[0] != '-') {
+			continue
+		}
+
+		if rest == "" {
+			return base, true, nil
+		}
+
+		offset, err := time.ParseDuration(rest)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return func(now time.Time) time.Time {
+			return base(now).Add(offset)
+		}, true, nil
+	}
+
+	return nil, false, nil
+}
+
 func parseTimeLayout(layout string) string {
 	if layout == "" {
 		return time.RFC3339
@@ -527,30 +857,50 @@ func structFieldsSetter(cfg *config, v reflect.Value) error {
 		return fmt.Errorf("[%s] type is not supported. must be struct", v.Kind())
 	}
 
-	typ := v.Type()
+	cfg.scopes = append(cfg.scopes, v)
+	defer func() { cfg.scopes = cfg.scopes[:len(cfg.scopes)-1] }()
+
+	for _, field := range getPlan(cfg, v.Type()).fields {
+		if err := cfg.ctx.Err(); err != nil {
+			return err
+		}
 
-	for i := 0; i < v.NumField(); i++ {
-		field := typ.Field(i)
-		val := v.Field(i)
-		key := fmt.Sprintf("%s.%s.%s", typ.PkgPath(), typ.Name(), field.Name)
+		val := v.Field(field.index)
+
+		if field.tag == "" {
+			cfg.observe(field, val, "untagged")
+			continue
+		}
 
 		if cfg.cache != nil {
-			if cached, ok := cfg.cache.get(key); ok {
+			if cached, ok := cfg.cache.Get(field.key); ok {
 				if cfg.deepCopy {
 					cached = deepCopy(cached)
 				}
 				val.Set(cached)
+				cfg.observe(field, val, "cached")
 				continue
 			}
 		}
 
-		if err := valueSetterRaw(cfg, val, field.Tag.Get(cfg.tag)); err != nil {
-			return err
+		volatile := cfg.volatile
+		cfg.volatile = false
+
+		cfg.path = append(cfg.path, field.name)
+		err := valueSetterParsed(cfg, val, field.cmd)
+		cfg.path = cfg.path[:len(cfg.path)-1]
+
+		if err != nil {
+			return wrapFieldError(field.name, err)
 		}
 
-		if cfg.cache != nil {
-			cfg.cache.set(key, val)
+		cfg.observe(field, val, "")
+
+		if cfg.cache != nil && !cfg.volatile {
+			cfg.cache.Set(field.key, val)
 		}
+
+		cfg.volatile = cfg.volatile || volatile
 	}
 
 	return nil
@@ -561,7 +911,46 @@ func valueSetterRaw(cfg *config, v reflect.Value, tag string) error {
 		return nil
 	}
 
-	return valueSetterCmd(cfg, v, parseTag(tag))
+	return valueSetterParsed(cfg, v, parseTag(tag))
+}
+
+func valueSetterParsed(cfg *config, v reflect.Value, cmd command) error {
+	if cfg.strict {
+		if err := cmd.validate(cfg.profiles); err != nil {
+			return err
+		}
+	} else if cfg.logger != nil {
+		if err := cmd.validate(cfg.profiles); err != nil {
+			cfg.logger.LogAttrs(cfg.ctx, slog.LevelWarn, "autostruct: ignored tag commands",
+				slog.String("path", strings.Join(cfg.path, ".")),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if cfg.profile != "" {
+		cmd = cmd.withProfile(cfg.profile)
+	}
+
+	if cmd.isProfileOnly() || cmd.isBindOnly() {
+		return nil
+	}
+
+	if cmd.isReference() {
+		if !v.CanSet() {
+			return fmt.Errorf("field is not exported: [%s]", v)
+		}
+
+		deferReference(cfg, v, cmd)
+		return nil
+	}
+
+	cmd, err := resolveSources(cfg, v, cmd)
+	if err != nil {
+		return err
+	}
+
+	return valueSetterCmd(cfg, v, cmd)
 }
 
 func valueSetterCmd(cfg *config, v reflect.Value, cmd command) error {
@@ -569,7 +958,7 @@ func valueSetterCmd(cfg *config, v reflect.Value, cmd command) error {
 		return fmt.Errorf("field is not exported: [%s]", v)
 	}
 
-	fn := getSetterFunc(v)
+	fn := getSetterFunc(cfg, v)
 	if fn == nil {
 		return fmt.Errorf("type is not supported: [%s]", v.Kind())
 	}
@@ -593,21 +982,46 @@ func deepCopy(srcVal reflect.Value) reflect.Value {
 
 	switch srcVal.Kind() {
 	case reflect.Pointer:
+		if srcVal.IsNil() {
+			return srcVal
+		}
 		copy := reflect.New(srcType.Elem())
 		copy.Elem().Set(deepCopy(srcVal.Elem()))
 		return copy
 	case reflect.Slice:
+		if srcVal.IsNil() {
+			return srcVal
+		}
 		copy := reflect.MakeSlice(srcType, srcVal.Len(), srcVal.Cap())
 		for i := 0; i < srcVal.Len(); i++ {
 			copy.Index(i).Set(deepCopy(srcVal.Index(i)))
 		}
 		return copy
+	case reflect.Array:
+		copy := reflect.New(srcType).Elem()
+		for i := 0; i < srcVal.Len(); i++ {
+			copy.Index(i).Set(deepCopy(srcVal.Index(i)))
+		}
+		return copy
 	case reflect.Map:
-		copy := reflect.MakeMap(srcType)
+		if srcVal.IsNil() {
+			return srcVal
+		}
+		copy := reflect.MakeMapWithSize(srcType, srcVal.Len())
 		for _, key := range srcVal.MapKeys() {
 			copy.SetMapIndex(key, deepCopy(srcVal.MapIndex(key)))
 		}
 		return copy
+	case reflect.Struct:
+		// unexported fields are shared, they are never set by a fill.
+		copy := reflect.New(srcType).Elem()
+		copy.Set(srcVal)
+		for i := 0; i < srcVal.NumField(); i++ {
+			if copy.Field(i).CanSet() {
+				copy.Field(i).Set(deepCopy(srcVal.Field(i)))
+			}
+		}
+		return copy
 	default:
 		return srcVal
 	}
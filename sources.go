@@ -0,0 +1,177 @@
+package autostruct
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+var sourceCommands = []string{"quote", "file", "base64", "hex", "seq", "rand", "secretfile"}
+
+func resolveSources(cfg *config, v reflect.Value, cmd command) (command, error) {
+	var (
+		name string
+		arg  string
+	)
+
+	for _, src := range sourceCommands {
+		if cmd.isCMD(src) {
+			name, arg = src, cmd.cmd(src)
+			break
+		}
+	}
+
+	if name == "" {
+		return cmd, nil
+	}
+
+	var (
+		val string
+		err error
+	)
+
+	switch name {
+	case "quote":
+		val, err = strconv.Unquote(arg)
+	case "file":
+		val, err = readFile(cfg, arg)
+		cfg.volatile = true
+	case "base64":
+		var b []byte
+		b, err = base64.StdEncoding.DecodeString(arg)
+		val = string(b)
+	case "hex":
+		var b []byte
+		b, err = hex.DecodeString(arg)
+		val = string(b)
+	case "seq":
+		val, err = sequenceValue(cfg, arg)
+		cfg.volatile = true
+		cfg.markUnstable(v)
+	case "rand":
+		val, err = randomValue(cfg, v.Type(), arg)
+		cfg.volatile = true
+		cfg.markUnstable(v)
+	case "secretfile":
+		val, err = readSecret(cfg, arg)
+		cfg.volatile = true
+	}
+
+	if err != nil {
+		return cmd, fmt.Errorf("command [%s]: %w", name, err)
+	}
+
+	list := make(map[string]string, len(cmd.list))
+	for k, v := range cmd.list {
+		if k != name {
+			list[k] = v
+		}
+	}
+
+	if isBytes(v.Type()) {
+		list["byte"] = val
+	} else {
+		list["value"] = val
+	}
+
+	return command{list: list}, nil
+}
+
+func readFile(cfg *config, name string) (string, error) {
+	fsys := cfg.fsys
+	if fsys == nil {
+		fsys = os.DirFS(".")
+	}
+
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func readSecret(cfg *config, name string) (string, error) {
+	fsys := cfg.secretFS
+	if fsys == nil {
+		fsys = os.DirFS("/")
+	}
+
+	b, err := fs.ReadFile(fsys, strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+func executeTemplate(text string, scope reflect.Value) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, scope.Addr().Interface()); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// templatePaths returns the fields a template reads from its data. Fields
+// inside range and with blocks are relative to a different dot and skipped.
+func templatePaths(node parse.Node) []string {
+	var paths []string
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				paths = append(paths, templatePaths(child)...)
+			}
+		}
+	case *parse.ActionNode:
+		paths = templatePaths(n.Pipe)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					paths = append(paths, templatePaths(arg)...)
+				}
+			}
+		}
+	case *parse.FieldNode:
+		paths = []string{strings.Join(n.Ident, ".")}
+	case *parse.IfNode:
+		paths = append(templatePaths(n.Pipe), templatePaths(n.List)...)
+		paths = append(paths, templatePaths(n.ElseList)...)
+	case *parse.RangeNode:
+		paths = templatePaths(n.Pipe)
+	case *parse.WithNode:
+		paths = templatePaths(n.Pipe)
+	case *parse.TemplateNode:
+		paths = templatePaths(n.Pipe)
+	}
+
+	return paths
+}
+
+func isBytes(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
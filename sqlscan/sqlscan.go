@@ -0,0 +1,138 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	autostruct "github.com/arsmn/auto-struct"
+)
+
+func ScanRow(rows *sql.Rows, v any, opts ...autostruct.Option) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if err := autostruct.Set(v, opts...); err != nil {
+		return err
+	}
+
+	fields := make(map[string]autostruct.Field)
+	err = autostruct.Walk(v, func(f autostruct.Field) error {
+		name, ok := f.Commands["db"]
+		if !ok {
+			name = f.Path[strings.LastIndex(f.Path, ".")+1:]
+		}
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = f
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	dest := make([]any, len(columns))
+	for i, column := range columns {
+		f, ok := fields[strings.ToLower(column)]
+		if !ok {
+			return fmt.Errorf("missing destination for column [%s]", column)
+		}
+		dest[i] = &scanner{field: f, opts: opts}
+	}
+
+	return rows.Scan(dest...)
+}
+
+func ScanAll[T any](rows *sql.Rows, opts ...autostruct.Option) ([]T, error) {
+	defer rows.Close()
+
+	var list []T
+	for rows.Next() {
+		var v T
+		if err := ScanRow(rows, &v, opts...); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+
+	return list, rows.Err()
+}
+
+func ScanOne[T any](rows *sql.Rows, opts ...autostruct.Option) (T, error) {
+	defer rows.Close()
+
+	var v T
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return v, err
+		}
+		return v, sql.ErrNoRows
+	}
+
+	if err := ScanRow(rows, &v, opts...); err != nil {
+		return v, err
+	}
+
+	return v, rows.Err()
+}
+
+type scanner struct {
+	field autostruct.Field
+	opts  []autostruct.Option
+}
+
+func (s *scanner) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+
+	v := s.field.Value
+	if sc, ok := v.Addr().Interface().(sql.Scanner); ok {
+		return s.wrap(sc.Scan(src))
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		elem := *s
+		elem.field.Value = v.Elem()
+		return elem.Scan(src)
+	}
+
+	switch src := src.(type) {
+	case []byte:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte(nil), src...))
+			return nil
+		}
+		return s.wrap(autostruct.SetString(v, s.field.Tag, string(src), s.opts...))
+	case string:
+		return s.wrap(autostruct.SetString(v, s.field.Tag, src, s.opts...))
+	case time.Time:
+		if v.Kind() == reflect.String {
+			v.SetString(src.Format(time.RFC3339Nano))
+			return nil
+		}
+	}
+
+	if sv := reflect.ValueOf(src); sv.Type().ConvertibleTo(v.Type()) && v.Kind() != reflect.String {
+		v.Set(sv.Convert(v.Type()))
+		return nil
+	}
+
+	return s.wrap(autostruct.SetString(v, s.field.Tag, fmt.Sprint(src), s.opts...))
+}
+
+func (s *scanner) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &autostruct.FieldError{Path: s.field.Path, Err: err}
+}
@@ -0,0 +1,149 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var table = struct {
+	columns []string
+	rows    [][]driver.Value
+}{
+	columns: []string{"id", "user_name", "role", "score", "created_at", "nickname", "deleted"},
+	rows: [][]driver.Value{
+		{int64(1), "alice", "admin", float64(9.5), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "al", nil},
+		{int64(2), []byte("bob"), nil, nil, nil, nil, int64(1)},
+	},
+}
+
+func init() {
+	sql.Register("sqlscan-stub", stubDriver{})
+}
+
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) {
+	return stubConn{}, nil
+}
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) {
+	return stubStmt{}, nil
+}
+
+func (stubConn) Close() error {
+	return nil
+}
+
+func (stubConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type stubStmt struct{}
+
+func (stubStmt) Close() error {
+	return nil
+}
+
+func (stubStmt) NumInput() int {
+	return 0
+}
+
+func (stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &stubRows{}, nil
+}
+
+type stubRows struct {
+	i int
+}
+
+func (r *stubRows) Columns() []string {
+	return table.columns
+}
+
+func (r *stubRows) Close() error {
+	return nil
+}
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.i >= len(table.rows) {
+		return io.EOF
+	}
+	copy(dest, table.rows[r.i])
+	r.i++
+	return nil
+}
+
+type User struct {
+	ID        int              `auto:"db(id)"`
+	Name      string           `auto:"db(user_name)"`
+	Role      string           `auto:"db(role),value(member)"`
+	Score     float64          `auto:"db(score),value(1.5)"`
+	CreatedAt *time.Time       `auto:"db(created_at)"`
+	Nickname  sql.Null[string] `auto:"db(nickname),value(anon)"`
+	Deleted   sql.NullBool     `auto:"db(deleted)"`
+}
+
+func query(t *testing.T) *sql.Rows {
+	db, err := sql.Open("sqlscan-stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rows
+}
+
+func Test_ScanAll(t *testing.T) {
+	act, err := ScanAll[User](query(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	exp := []User{
+		{ID: 1, Name: "alice", Role: "admin", Score: 9.5, CreatedAt: &created, Nickname: sql.Null[string]{V: "al", Valid: true}},
+		{ID: 2, Name: "bob", Role: "member", Score: 1.5, Nickname: sql.Null[string]{V: "anon", Valid: true}, Deleted: sql.NullBool{Bool: true, Valid: true}},
+	}
+
+	if !cmp.Equal(exp, act) {
+		t.Error(cmp.Diff(exp, act))
+	}
+}
+
+func Test_ScanOne(t *testing.T) {
+	act, err := ScanOne[User](query(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if act.ID != 1 || act.Name != "alice" {
+		t.Errorf("unexpected row %+v", act)
+	}
+}
+
+func Test_ScanRowMissingColumn(t *testing.T) {
+	type Partial struct {
+		ID int `auto:"db(id)"`
+	}
+
+	if _, err := ScanAll[Partial](query(t)); err == nil {
+		t.Error("expected error for unmapped columns")
+	}
+}
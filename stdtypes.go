@@ -0,0 +1,146 @@
+package autostruct
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var stdSetters = map[reflect.Type]setterFunc{
+	reflect.TypeOf(url.URL{}):        urlSetter,
+	reflect.TypeOf(&url.URL{}):       urlSetter,
+	reflect.TypeOf(mail.Address{}):   mailAddressSetter,
+	reflect.TypeOf(&mail.Address{}):  mailAddressSetter,
+	reflect.TypeOf(&regexp.Regexp{}): regexpSetter,
+	reflect.TypeOf(&time.Location{}): locationSetter,
+	reflect.TypeOf(time.Month(0)):    monthSetter,
+	reflect.TypeOf(time.Weekday(0)):  weekdaySetter,
+	reflect.TypeOf(net.IP{}):         textSetter,
+	reflect.TypeOf(netip.Addr{}):     textSetter,
+	reflect.TypeOf(netip.Prefix{}):   textSetter,
+	reflect.TypeOf(netip.AddrPort{}): textSetter,
+	reflect.TypeOf(big.Int{}):        bigSetter,
+	reflect.TypeOf(big.Float{}):      bigSetter,
+	reflect.TypeOf(big.Rat{}):        bigSetter,
+}
+
+func textSetter(_ *config, v reflect.Value, cmd command) error {
+	if !v.CanAddr() {
+		return fmt.Errorf("TextSetter does not support [%s]", v.Type())
+	}
+
+	u, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return fmt.Errorf("TextSetter does not support [%s]", v.Type())
+	}
+
+	return u.UnmarshalText([]byte(cmd.value()))
+}
+
+func bigSetter(cfg *config, v reflect.Value, cmd command) error {
+	// big numbers share their backing arrays when copied, so a cached value
+	// could be modified in place through any of its copies.
+	cfg.volatile = true
+	return textSetter(cfg, v, cmd)
+}
+
+func urlSetter(_ *config, v reflect.Value, cmd command) error {
+	u, err := url.Parse(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	return setPointerOrValue(v, reflect.ValueOf(u))
+}
+
+func mailAddressSetter(_ *config, v reflect.Value, cmd command) error {
+	addr, err := mail.ParseAddress(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	return setPointerOrValue(v, reflect.ValueOf(addr))
+}
+
+func regexpSetter(_ *config, v reflect.Value, cmd command) error {
+	rx, err := regexp.Compile(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	return setPointerOrValue(v, reflect.ValueOf(rx))
+}
+
+func locationSetter(_ *config, v reflect.Value, cmd command) error {
+	loc, err := time.LoadLocation(cmd.value())
+	if err != nil {
+		return err
+	}
+
+	return setPointerOrValue(v, reflect.ValueOf(loc))
+}
+
+func monthSetter(_ *config, v reflect.Value, cmd command) error {
+	i, err := parseNamed(cmd.value(), 1, 12, func(i int) string {
+		return time.Month(i).String()
+	})
+	if err != nil {
+		return err
+	}
+
+	v.SetInt(int64(i))
+
+	return nil
+}
+
+func weekdaySetter(_ *config, v reflect.Value, cmd command) error {
+	i, err := parseNamed(cmd.value(), 0, 6, func(i int) string {
+		return time.Weekday(i).String()
+	})
+	if err != nil {
+		return err
+	}
+
+	v.SetInt(int64(i))
+
+	return nil
+}
+
+func parseNamed(s string, lo, hi int, name func(int) string) (int, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		if i < lo || i > hi {
+			return 0, fmt.Errorf("value [%d] is out of range [%d-%d]", i, lo, hi)
+		}
+		return i, nil
+	}
+
+	for i := lo; i <= hi; i++ {
+		if n := name(i); strings.EqualFold(s, n) || strings.EqualFold(s, n[:3]) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown name [%s]", s)
+}
+
+func setPointerOrValue(v, ptr reflect.Value) error {
+	switch v.Type() {
+	case ptr.Type():
+		v.Set(ptr)
+	case ptr.Type().Elem():
+		v.Set(ptr.Elem())
+	default:
+		return fmt.Errorf("cannot assign [%s] to [%s]", ptr.Type(), v.Type())
+	}
+
+	return nil
+}
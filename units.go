@@ -0,0 +1,82 @@
+package autostruct
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	bytesUnits = map[string]*big.Rat{
+		"":    big.NewRat(1, 1),
+		"B":   big.NewRat(1, 1),
+		"KB":  pow(1000, 1),
+		"MB":  pow(1000, 2),
+		"GB":  pow(1000, 3),
+		"TB":  pow(1000, 4),
+		"PB":  pow(1000, 5),
+		"EB":  pow(1000, 6),
+		"KiB": pow(1024, 1),
+		"MiB": pow(1024, 2),
+		"GiB": pow(1024, 3),
+		"TiB": pow(1024, 4),
+		"PiB": pow(1024, 5),
+		"EiB": pow(1024, 6),
+	}
+	siUnits = map[string]*big.Rat{
+		"":  big.NewRat(1, 1),
+		"n": new(big.Rat).Inv(pow(1000, 3)),
+		"u": new(big.Rat).Inv(pow(1000, 2)),
+		"µ": new(big.Rat).Inv(pow(1000, 2)),
+		"m": new(big.Rat).Inv(pow(1000, 1)),
+		"k": pow(1000, 1),
+		"M": pow(1000, 2),
+		"G": pow(1000, 3),
+		"T": pow(1000, 4),
+		"P": pow(1000, 5),
+		"E": pow(1000, 6),
+	}
+	percentUnits = map[string]*big.Rat{
+		"":  big.NewRat(1, 100),
+		"%": big.NewRat(1, 100),
+	}
+	unitCommands = map[string]map[string]*big.Rat{
+		"bytes":   bytesUnits,
+		"si":      siUnits,
+		"percent": percentUnits,
+	}
+)
+
+func pow(base, exp int64) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(base), big.NewInt(exp), nil))
+}
+
+func parseUnit(name, value string) (*big.Rat, error) {
+	units, ok := unitCommands[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit command [%s]", name)
+	}
+
+	value = strings.TrimSpace(value)
+
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !strings.ContainsRune("+-0123456789._", r)
+	})
+	if i < 0 {
+		i = len(value)
+	}
+
+	num, suffix := value[:i], strings.TrimSpace(value[i:])
+
+	multiplier, ok := units[suffix]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit [%s] in [%s]", suffix, value)
+	}
+
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(num, "_", ""))
+	if !ok {
+		return nil, fmt.Errorf("invalid number [%s] in [%s]", num, value)
+	}
+
+	return r.Mul(r, multiplier), nil
+}
@@ -0,0 +1,280 @@
+package autostruct
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type Defaulted[T any] struct {
+	Value T
+}
+
+func (d Defaulted[T]) Get() T {
+	return d.Value
+}
+
+func (d Defaulted[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Value)
+}
+
+func (d *Defaulted[T]) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, &d.Value)
+}
+
+func (d *Defaulted[T]) UnmarshalText(text []byte) error {
+	cfg := newConfig(context.Background())
+	v := reflect.ValueOf(&d.Value).Elem()
+
+	if err := fillDefaults(cfg, v); err != nil {
+		return err
+	}
+
+	if u, ok := any(&d.Value).(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText(text)
+	}
+
+	return valueSetterCmd(cfg, v, command{list: map[string]string{"value": string(text)}})
+}
+
+func UnmarshalJSON(data []byte, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("[%T] must be a non-nil pointer", v)
+	}
+
+	cfg := newConfig(context.Background(), opts...)
+	if err := fillDefaults(cfg, rv.Elem()); err != nil {
+		return err
+	}
+
+	return decodeJSON(cfg, rv.Elem(), data)
+}
+
+func fillDefaults(cfg *config, v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return cfg.fill(v)
+}
+
+func decodeJSON(cfg *config, v reflect.Value, data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	if ptr := v.Addr().Type(); ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+			if err := fillDefaults(cfg, v.Elem()); err != nil {
+				return err
+			}
+		}
+		return decodeJSON(cfg, v.Elem(), data)
+	case reflect.Struct:
+		return decodeStruct(cfg, v, data)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return json.Unmarshal(data, v.Addr().Interface())
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeElem(cfg, s.Index(i), item); err != nil {
+				return wrapFieldError(strconv.Itoa(i), err)
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return json.Unmarshal(data, v.Addr().Interface())
+		}
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+			if i >= len(items) {
+				continue
+			}
+			if err := decodeElem(cfg, v.Index(i), items[i]); err != nil {
+				return wrapFieldError(strconv.Itoa(i), err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return json.Unmarshal(data, v.Addr().Interface())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(items)))
+		}
+		for key, item := range items {
+			k, err := decodeMapKey(v.Type().Key(), key)
+			if err != nil {
+				return json.Unmarshal(data, v.Addr().Interface())
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeElem(cfg, elem, item); err != nil {
+				return wrapFieldError(key, err)
+			}
+			v.SetMapIndex(k, elem)
+		}
+		return nil
+	}
+
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+func decodeElem(cfg *config, v reflect.Value, data []byte) error {
+	if err := fillDefaults(cfg, v); err != nil {
+		return err
+	}
+
+	return decodeJSON(cfg, v, data)
+}
+
+func decodeMapKey(typ reflect.Type, key string) (reflect.Value, error) {
+	k := reflect.New(typ)
+	if u, ok := k.Interface().(encoding.TextUnmarshaler); ok {
+		return k.Elem(), u.UnmarshalText([]byte(key))
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		k.Elem().SetString(key)
+		return k.Elem(), nil
+	default:
+		return k.Elem(), json.Unmarshal([]byte(key), k.Interface())
+	}
+}
+
+func decodeStruct(cfg *config, v reflect.Value, data []byte) error {
+	var items map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	fields := jsonFields(v.Type())
+	for key, item := range items {
+		field, ok := fields[key]
+		if !ok {
+			for name, f := range fields {
+				if strings.EqualFold(name, key) {
+					field, ok = f, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		fv, err := fieldByIndex(v, field.index)
+		if err != nil {
+			return err
+		}
+
+		if field.quoted {
+			var s string
+			if err := json.Unmarshal(item, &s); err == nil {
+				item = []byte(s)
+			}
+		}
+
+		if err := decodeJSON(cfg, fv, item); err != nil {
+			return wrapFieldError(field.name, err)
+		}
+	}
+
+	return nil
+}
+
+type jsonField struct {
+	name   string
+	index  []int
+	quoted bool
+}
+
+func jsonFields(typ reflect.Type) map[string]jsonField {
+	fields := make(map[string]jsonField)
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			t := f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				for key, inner := range jsonFields(t) {
+					if _, ok := fields[key]; !ok {
+						inner.index = append([]int{i}, inner.index...)
+						fields[key] = inner
+					}
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields[name] = jsonField{
+			name:   f.Name,
+			index:  []int{i},
+			quoted: strings.Contains(opts, "string"),
+		}
+	}
+
+	return fields
+}
+
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct [%s]", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package autostruct
+
+import (
+	"context"
+	"reflect"
+)
+
+type Field struct {
+	Path     string
+	Tag      string
+	Commands map[string]string
+	Value    reflect.Value
+}
+
+func Walk(v any, fn func(Field) error, opts ...Option) error {
+	return walkFields(newConfig(context.Background(), opts...), reflect.ValueOf(v), "", fn)
+}
+
+func walkFields(cfg *config, v reflect.Value, prefix string, fn func(Field) error) error {
+	v = indirect(v)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range getPlan(cfg, v.Type()).fields {
+		if !v.Type().Field(field.index).IsExported() {
+			continue
+		}
+
+		f := Field{Path: field.name, Tag: field.tag, Value: v.Field(field.index)}
+		if prefix != "" {
+			f.Path = prefix + "." + field.name
+		}
+
+		if field.tag != "" {
+			cmd := field.cmd
+			if cfg.profile != "" {
+				cmd = cmd.withProfile(cfg.profile)
+			}
+
+			f.Commands = make(map[string]string, len(cmd.list))
+			for name, arg := range cmd.list {
+				f.Commands[name] = arg
+			}
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+
+		if elem := indirect(f.Value); elem.IsValid() && elem.Kind() == reflect.Struct && !isOpaque(elem.Type()) {
+			if err := walkFields(cfg, elem, f.Path, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func SetString(v reflect.Value, tag, s string, opts ...Option) error {
+	cfg := newConfig(context.Background(), opts...)
+
+	cmd := command{list: map[string]string{"value": s}}
+	if isBytes(v.Type()) {
+		cmd = command{list: map[string]string{"byte": s}}
+	}
+	for _, name := range []string{"layout", "tz"} {
+		if arg, ok := parseTag(tag).list[name]; ok {
+			cmd.list[name] = arg
+		}
+	}
+
+	return valueSetterCmd(cfg, v, cmd)
+}
@@ -0,0 +1,166 @@
+package autostruct
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type validator interface {
+	Validate() error
+}
+
+type Watcher[T any] struct {
+	path     string
+	interval time.Duration
+	opts     []Option
+	value    atomic.Pointer[T]
+
+	lock     sync.Mutex
+	modTime  time.Time
+	size     int64
+	err      error
+	onChange []func(old, new *T, paths []string)
+	onError  []func(err error)
+}
+
+func NewWatcher[T any](path string, interval time.Duration, opts ...Option) (*Watcher[T], error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval [%s] must be positive", interval)
+	}
+
+	w := &Watcher[T]{
+		path:     path,
+		interval: interval,
+		opts:     opts,
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Watcher[T]) Load() *T {
+	return w.value.Load()
+}
+
+func (w *Watcher[T]) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.err
+}
+
+func (w *Watcher[T]) OnChange(fn func(old, new *T, paths []string)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.onChange = append(w.onChange, fn)
+}
+
+func (w *Watcher[T]) OnError(fn func(err error)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.onError = append(w.onError, fn)
+}
+
+func (w *Watcher[T]) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if w.changed() {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) Reload() error {
+	w.lock.Lock()
+
+	old := w.value.Load()
+	v, info, err := w.load()
+	if err != nil {
+		w.err = fmt.Errorf("reload [%s]: %w", w.path, err)
+		if info != nil {
+			w.modTime, w.size = info.ModTime(), info.Size()
+		}
+		err, callbacks := w.err, w.onError
+		w.lock.Unlock()
+
+		for _, fn := range callbacks {
+			fn(err)
+		}
+		return err
+	}
+
+	w.value.Store(v)
+	w.modTime, w.size, w.err = info.ModTime(), info.Size(), nil
+	callbacks := w.onChange
+	w.lock.Unlock()
+
+	if old == nil {
+		return nil
+	}
+
+	paths := diffPaths(reflect.ValueOf(*old), reflect.ValueOf(*v))
+	if len(paths) == 0 {
+		return nil
+	}
+
+	for _, fn := range callbacks {
+		fn(old, v, paths)
+	}
+
+	return nil
+}
+
+func (w *Watcher[T]) changed() bool {
+	info, err := os.Stat(w.path)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err != nil {
+		return w.err == nil
+	}
+
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+func (w *Watcher[T]) load() (*T, os.FileInfo, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, info, err
+	}
+
+	v := new(T)
+	if err := UnmarshalJSON(data, v, w.opts...); err != nil {
+		return nil, info, err
+	}
+
+	if val, ok := any(v).(validator); ok {
+		if err := val.Validate(); err != nil {
+			return nil, info, err
+		}
+	}
+
+	return v, info, nil
+}